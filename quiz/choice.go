@@ -0,0 +1,117 @@
+package quiz
+
+import (
+	"fmt"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+	"strings"
+)
+
+const maxOptions = 'Z' - 'A' + 1
+
+func OptionLetter(idx int) string {
+	return string(rune('A' + idx))
+}
+
+// optionIndex finds the option selected by a response, which is either the
+// letter shown in the prompt or the text of an option, ignoring case and width.
+// A single letter always picks the option shown with it, even if another option
+// has that letter as its text. It returns -1 if the selection does not match any option
+func optionIndex(options []string, selection string) int {
+	selection = width.Fold.String(norm.NFC.String(strings.TrimSpace(selection)))
+	if idx := letterIndex(options, selection); idx >= 0 {
+		return idx
+	}
+	return textIndex(options, selection)
+}
+
+// answerIndex finds the option given as the answer of a question in a quiz file.
+// Unlike a response, the text of an option wins over a letter, so that every
+// option can be the answer even if its text is the letter of another option
+func answerIndex(options []string, answer string) int {
+	answer = width.Fold.String(norm.NFC.String(strings.TrimSpace(answer)))
+	if idx := textIndex(options, answer); idx >= 0 {
+		return idx
+	}
+	return letterIndex(options, answer)
+}
+
+func textIndex(options []string, selection string) int {
+	for idx, option := range options {
+		if strings.EqualFold(width.Fold.String(option), selection) {
+			return idx
+		}
+	}
+	return -1
+}
+
+func letterIndex(options []string, selection string) int {
+	if len(selection) == 1 {
+		idx := int(strings.ToUpper(selection)[0]) - 'A'
+		if idx >= 0 && idx < len(options) {
+			return idx
+		}
+	}
+	return -1
+}
+
+func (question *Question) IsMultipleChoice() bool {
+	return len(question.Options) > 0
+}
+
+// Prompt is the text shown in the terminal when asking the question. A question
+// of several lines starts on the line after its number. See Question.Render
+func (question *Question) Prompt(number int, color bool) string {
+	var sb strings.Builder
+	text := question.Render(color)
+	if strings.Contains(text, "\n") {
+		fmt.Fprintf(&sb, "Question #%d:\n%s\n", number, text)
+	} else {
+		fmt.Fprintf(&sb, "Question #%d: %s\n", number, text)
+	}
+	for idx, option := range question.Options {
+		fmt.Fprintf(&sb, "  %s) %s\n", OptionLetter(idx), option)
+	}
+	if question.IsMultiPart() {
+		fmt.Fprintf(&sb, "  (%d parts, separated by %q)\n", len(question.Parts), partSeparator)
+	}
+	if question.IsCode() {
+		fmt.Fprintf(&sb, "  (write the Go code, then %s on a line of its own)\n", EndCommand)
+	}
+	sb.WriteString("> ")
+	return sb.String()
+}
+
+// IsCorrect checks the response to the question. Multiple choice questions are
+// scored by the option which was picked rather than by the text of the response.
+// Other questions are matched with the question's own matcher, or the default
+// matcher if the question has none, against the answer and any of its aliases.
+// Multi-part questions are only correct if every part was answered, and code
+// questions if every test passed
+func (record *Record) IsCorrect(defaultMatcher Matcher) bool {
+	switch {
+	case record.IsMultipleChoice():
+		idx := optionIndex(record.Options, record.Response)
+		return idx >= 0 && record.Options[idx] == record.Answer
+	case record.IsMultiPart(), record.IsCode():
+		return record.Credit(defaultMatcher) == 1
+	}
+
+	matcher := record.matcherOr(defaultMatcher)
+	if matcher.Match(record.Answer, record.Response) {
+		return true
+	}
+	for _, alias := range record.Aliases {
+		if matcher.Match(alias, record.Response) {
+			return true
+		}
+	}
+	return false
+}
+
+func (question *Question) matcherOr(defaultMatcher Matcher) Matcher {
+	if question.Matcher != nil {
+		return question.Matcher
+	}
+	return defaultMatcher
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"gophercises.com/quiz/store"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+)
+
+const (
+	defaultDbPath = "quiz.db"
+	defaultMissed = 5
+)
+
+func saveAttempt(dbPath string, attempt *store.Attempt) error {
+	db, err := store.Open(dbPath)
+	if err != nil {
+		return err
+	}
+
+	//goland:noinspection GoUnhandledErrorResult
+	defer db.Close()
+	return db.Record(attempt)
+}
+
+// historyCommand shows how a user has progressed across their attempts
+func historyCommand(args []string) {
+	var (
+		user, dbPath string
+		missed       int
+		flags        = flag.NewFlagSet("history", flag.ExitOnError)
+	)
+	flags.StringVar(&user, "user", "", "The user whose history is shown")
+	flags.StringVar(&dbPath, "db", defaultDbPath, "The database where the quiz history is kept")
+	flags.IntVar(&missed, "missed", defaultMissed, "The number of most missed questions to show")
+	//goland:noinspection GoUnhandledErrorResult
+	flags.Parse(args)
+
+	if user == "" {
+		log.Fatal("A user is required to show their history")
+	}
+	if _, err := os.Stat(dbPath); errors.Is(err, os.ErrNotExist) {
+		log.Fatalf("No quiz history found in %s\n", dbPath)
+	}
+
+	db, err := store.Open(dbPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	//goland:noinspection GoUnhandledErrorResult
+	defer db.Close()
+	attempts, err := db.Attempts(user)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(attempts) == 0 {
+		fmt.Printf("%s has not taken any quizzes yet\n", user)
+		return
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	printHistory(os.Stdout, user, attempts, missed)
+}
+
+func printHistory(w io.Writer, user string, attempts []store.Attempt, missed int) error {
+	var (
+		tw       = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		previous = make(map[string]float64)
+		best     = make(map[string]*store.Attempt)
+		quizzes  []string
+	)
+
+	fmt.Fprintf(tw, "Quiz history for %s\n\n", user)
+	fmt.Fprintln(tw, "Date\tQuiz\tScore\tPercent\tChange")
+	for idx := range attempts {
+		attempt := &attempts[idx]
+		percent := attempt.Report.Percent()
+		change := ""
+		if last, found := previous[attempt.Quiz]; found {
+			change = fmt.Sprintf("%+.1f%%", percent-last)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d/%d\t%.1f%%\t%s\n", attempt.Time.Local().Format("2006-01-02 15:04"),
+			attempt.Quiz, attempt.Report.Correct, attempt.Report.Total, percent, change)
+
+		previous[attempt.Quiz] = percent
+		if top, found := best[attempt.Quiz]; !found {
+			quizzes = append(quizzes, attempt.Quiz)
+			best[attempt.Quiz] = attempt
+		} else if percent > top.Report.Percent() {
+			best[attempt.Quiz] = attempt
+		}
+	}
+
+	fmt.Fprintln(tw, "\nBest scores")
+	for _, quiz := range quizzes {
+		top := best[quiz]
+		fmt.Fprintf(tw, "%s\t%d/%d\t%.1f%%\ton %s\n", quiz, top.Report.Correct, top.Report.Total,
+			top.Report.Percent(), top.Time.Local().Format("2006-01-02"))
+	}
+
+	if misses := mostMissed(attempts); len(misses) > 0 && missed > 0 {
+		fmt.Fprintln(tw, "\nMost missed questions")
+		if len(misses) > missed {
+			misses = misses[:missed]
+		}
+		for _, miss := range misses {
+			fmt.Fprintf(tw, "%dx\t%s\n", miss.count, miss.question)
+		}
+	}
+	return tw.Flush()
+}
+
+type missCount struct {
+	question string
+	count    int
+}
+
+// mostMissed counts how often each question was answered wrong or timed out. The
+// questions which were never reached are not counted, since they were not missed
+func mostMissed(attempts []store.Attempt) []missCount {
+	var (
+		counts = make(map[string]int)
+		misses []missCount
+	)
+	for _, attempt := range attempts {
+		for _, entry := range attempt.Report.Questions {
+			if entry.Reached() && !entry.Correct {
+				counts[entry.Question]++
+			}
+		}
+	}
+	for question, count := range counts {
+		misses = append(misses, missCount{question: question, count: count})
+	}
+	sort.Slice(misses, func(i, j int) bool {
+		if misses[i].count != misses[j].count {
+			return misses[i].count > misses[j].count
+		}
+		return misses[i].question < misses[j].question
+	})
+	return misses
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"gophercises.com/quiz"
+	"gophercises.com/quiz/store"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newAttempt reports an attempt at the questions 1+1, 2+2 and 3+3 with the
+// responses, where an empty response was not answered and a missing one was
+// not reached
+func newAttempt(quizName string, day int, responses ...string) *store.Attempt {
+	records := []quiz.Record{
+		{Question: quiz.Question{Text: "1+1", Answer: "2"}},
+		{Question: quiz.Question{Text: "2+2", Answer: "4"}},
+		{Question: quiz.Question{Text: "3+3", Answer: "6"}},
+	}
+	for idx, response := range responses {
+		records[idx].Asked, records[idx].Answered, records[idx].Response = true, response != "", response
+	}
+	matcher, _ := quiz.ParseMatcher(quiz.DefaultMatch)
+	return &store.Attempt{User: "ann", Quiz: quizName, Time: time.Date(2022, 10, day, 12, 0, 0, 0, time.UTC), Report: quiz.NewReport(records, matcher, 0)}
+}
+
+// recordAttempts saves the attempts to a temporary bolt file and reads them back
+func recordAttempts(t *testing.T, attempts ...*store.Attempt) []store.Attempt {
+	t.Helper()
+	dbPath := filepath.Join(t.TempDir(), "quiz.db")
+	for _, attempt := range attempts {
+		if err := saveAttempt(dbPath, attempt); err != nil {
+			t.Fatalf("saveAttempt() received an error: %v", err)
+		}
+	}
+	db, err := store.Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open() received an error: %v", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer db.Close()
+	saved, err := db.Attempts("ann")
+	if err != nil {
+		t.Fatalf("Attempts() received an error: %v", err)
+	}
+	return saved
+}
+
+func TestMostMissed(t *testing.T) {
+	attempts := recordAttempts(t,
+		newAttempt("math", 1, "2", "5", ""),
+		newAttempt("math", 2, "3"),
+		newAttempt("math", 3, "2", "4", "6"),
+	)
+	misses := mostMissed(attempts)
+	//3+3 timed out once and was not reached once, which is not a miss
+	want := []missCount{{question: "1+1", count: 1}, {question: "2+2", count: 1}, {question: "3+3", count: 1}}
+	if len(misses) != len(want) {
+		t.Fatalf("mostMissed(): want %v, got %v", want, misses)
+	}
+	for idx := range want {
+		if misses[idx] != want[idx] {
+			t.Errorf("mostMissed(): want %v, got %v", want, misses)
+		}
+	}
+
+	attempts = recordAttempts(t, newAttempt("math", 1, "3", "5"), newAttempt("math", 2, "3"))
+	if misses = mostMissed(attempts); len(misses) != 2 || misses[0] != (missCount{question: "1+1", count: 2}) {
+		t.Errorf("mostMissed(): want the most missed question first, got %v", misses)
+	}
+}
+
+func TestPrintHistory(t *testing.T) {
+	attempts := recordAttempts(t,
+		newAttempt("math", 1, "2", "5", ""),
+		newAttempt("capitals", 2, "2", "4", "6"),
+		newAttempt("math", 3, "2", "4", "7"),
+		newAttempt("math", 4, "3"),
+	)
+	var out bytes.Buffer
+	if err := printHistory(&out, "ann", attempts, 1); err != nil {
+		t.Fatalf("printHistory() received an error: %v", err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	for _, want := range []string{
+		"Quiz history for ann",
+		"math      1/3    33.3%    ",
+		"math      2/3    66.7%    +33.3%",
+		"math      0/3    0.0%     -66.7%",
+		"capitals  3/3    100.0%   ",
+		"Best scores",
+		"math      2/3  66.7%   on ",
+		"Most missed questions",
+		"2x  3+3",
+	} {
+		var found bool
+		for _, line := range lines {
+			if strings.Contains(line, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("printHistory(): want %q in the history, got:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "2+2") || strings.Contains(out.String(), "1+1") {
+		t.Errorf("printHistory(): want only the most missed question, got:\n%s", out.String())
+	}
+}
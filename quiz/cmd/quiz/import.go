@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"gophercises.com/quiz"
+	"io"
+	"log"
+	"os"
+)
+
+// importCommand converts the cards exported by Anki or Quizlet into a quiz file
+func importCommand(args []string) {
+	var (
+		from, out, format string
+		tags              string
+		importer          quiz.Importer
+		flags             = flag.NewFlagSet("import", flag.ExitOnError)
+	)
+	flags.StringVar(&from, "from", "", "The tool which exported the cards: anki for a 'Notes in Plain Text' export, or quizlet")
+	flags.StringVar(&out, "out", "", "The quiz file to create. The questions are written to the standard output by default")
+	flags.StringVar(&format, "format", "", "The format of the quiz file (csv, json, yaml or toml). Detected from the file extension by default, or csv on the standard output")
+	flags.StringVar(&importer.Separator, "separator", "", "The separator between the fields of a card, a tab by default. Names such as tab, comma or semicolon are accepted. Anki exports which name their separator use it instead")
+	flags.StringVar(&importer.CardSeparator, "card-separator", "", "The separator between the cards of a Quizlet export, a new line by default")
+	flags.StringVar(&importer.Category, "category", "", "The category of the questions, unless an Anki export has the deck of each card")
+	flags.StringVar(&tags, "tags", "", "Tags to add to every question, separated by commas")
+	flags.BoolVar(&importer.Reverse, "reverse", false, "Ask for the front of each card given its back, eg. the term given its definition")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: import -from anki|quizlet [flags] [export file]\n\nReads the standard input when no export file is given\n")
+		flags.PrintDefaults()
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	flags.Parse(args)
+
+	switch from {
+	case quiz.ImportAnki, quiz.ImportQuizlet:
+	case "":
+		log.Fatal("The tool which exported the cards is required, with -from anki or -from quizlet")
+	default:
+		log.Fatalf("Unknown export format %q, it must be anki or quizlet\n", from)
+	}
+	importer.From, importer.Tags = from, splitList(tags)
+	if out == "" && format == "" {
+		format = "csv"
+	}
+	format, err := quiz.SourceFormat(out, format)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var in io.Reader = os.Stdin
+	if path := flags.Arg(0); path != "" && path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("Failed to open the export: %v\n", err)
+		}
+		//goland:noinspection GoUnhandledErrorResult
+		defer file.Close()
+		in = file
+	}
+
+	//nothing is written unless every card is imported
+	var bank bytes.Buffer
+	count, err := importer.Import(in, &bank, format)
+	if err != nil {
+		log.Fatalf("Failed to import the cards: %v\n", err)
+	}
+	if out == "" {
+		//goland:noinspection GoUnhandledErrorResult
+		os.Stdout.Write(bank.Bytes())
+		return
+	}
+	if err := writeNewFile(out, bank.Bytes()); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Imported %d questions to %s\n", count, out)
+}
+
+// writeNewFile creates the file, so that an existing quiz file is not overwritten
+func writeNewFile(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s already exists, choose another file to import to", path)
+	} else if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		//goland:noinspection GoUnhandledErrorResult
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
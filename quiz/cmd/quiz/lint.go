@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"gophercises.com/quiz"
+	"os"
+)
+
+// lintCommand checks question banks for problems and exits with a non-zero
+// code if any are found, so that it can gate changes to a bank
+func lintCommand(args []string) {
+	var (
+		format string
+		flags  = flag.NewFlagSet("lint", flag.ExitOnError)
+	)
+	flags.StringVar(&format, "format", "", "The format of the quiz files (csv, json, yaml or toml). Detected from the file extensions by default")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: lint [flags] [quiz files]\n\nChecks %s when no quiz files are given\n", defaultQuizFile)
+		flags.PrintDefaults()
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	flags.Parse(args)
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{defaultQuizFile}
+	}
+
+	failed := false
+	for _, path := range paths {
+		issues, err := lintFile(path, format)
+		for _, issue := range issues {
+			fmt.Printf("%s:%d:%d: %s\n", path, issue.Line, issue.Column, issue.Message)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		}
+		if err != nil || len(issues) > 0 {
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func lintFile(path, format string) ([]quiz.LintIssue, error) {
+	format, err := quiz.SourceFormat(path, format)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %v", path, err)
+	}
+
+	//goland:noinspection GoUnhandledErrorResult
+	defer file.Close()
+	return quiz.Lint(format, file)
+}
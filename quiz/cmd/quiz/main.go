@@ -0,0 +1,460 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"golang.org/x/term"
+	"gophercises.com/quiz"
+	"gophercises.com/quiz/store"
+	"gophercises.com/quiz/tui"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultQuizFile = "problems.csv"
+	defaultDuration = 30
+	defaultShuffle  = false
+	defaultHintCost = 0.5
+	// defaultCodeMemory is the memory limit of the tests of code questions, in megabytes
+	defaultCodeMemory  = 256
+	defaultCodeTimeout = 10 * time.Second
+
+	generateDefault   = "default"
+	generatedQuizName = "arithmetic"
+)
+
+// quizOptions are the flags shared by every command which runs a quiz
+type quizOptions struct {
+	quizPath    string
+	generate    string
+	format      string
+	timeLimit   int
+	perQuestion time.Duration
+	shuffle     bool
+	match       string
+	penalty     float64
+	categories  string
+	tags        string
+	count       int
+	seed        int64
+	lang        string
+	codeTimeout time.Duration
+	codeMemory  int
+}
+
+func (opts *quizOptions) register(flags *flag.FlagSet) {
+	flags.StringVar(&opts.quizPath, "quiz", defaultQuizFile, "A quiz file, eg. a csv file in the format of 'question,answer'")
+	flags.StringVar(&opts.generate, "generate", "", "Generate arithmetic questions instead of reading a quiz file, eg. 'ops=+-*/,min=1,max=12,count=20' or 'ops=*,digits=2'. Use 'default' for additions and subtractions up to 10")
+	flags.StringVar(&opts.format, "format", "", "The format of the quiz file (csv, json, yaml or toml). Detected from the file extension by default")
+	flags.IntVar(&opts.timeLimit, "duration", defaultDuration, "A time limit for the quiz, in seconds")
+	flags.BoolVar(&opts.shuffle, "shuffle", defaultShuffle, "Shuffle the quiz questions?")
+	flags.DurationVar(&opts.perQuestion, "per-question", 0, "A default time limit for each question, eg. 10s. Questions may set their own limit in the quiz file")
+	flags.Float64Var(&opts.penalty, "penalty", 0, "Negative marking: the share of its points a question loses when answered wrong, eg. 0.25. Unanswered questions are not penalised")
+	flags.StringVar(&opts.categories, "category", "", "Only ask the questions in these comma separated categories")
+	flags.StringVar(&opts.tags, "tag", "", "Only ask the questions with any of these comma separated tags")
+	flags.IntVar(&opts.count, "count", 0, "Ask at most this many questions, picked at random, from each category")
+	flags.Int64Var(&opts.seed, "seed", 0, "Seed the random sampling and shuffling to replay a previous session. A new seed is picked by default")
+	flags.StringVar(&opts.lang, "lang", "", "Ask the questions in this language, eg. fr or ja, if the quiz file has translations to it")
+	flags.DurationVar(&opts.codeTimeout, "code-timeout", defaultCodeTimeout, "The time limit of each test of a code question")
+	flags.IntVar(&opts.codeMemory, "code-memory", defaultCodeMemory, "The memory limit of each test of a code question, in megabytes. Only enforced on unix")
+	flags.StringVar(&opts.match, "match", quiz.DefaultMatch, "The default strategy for matching answers: exact, nocase, space, nfkc, noaccent, width, numeric[:tolerance] or regex[:pattern]. Text strategies can be combined, eg. nocase+noaccent+width")
+}
+
+func (opts *quizOptions) duration() time.Duration {
+	return time.Duration(opts.timeLimit) * time.Second
+}
+
+// grader runs the tests of code questions with the limits of the flags
+func (opts *quizOptions) grader() *quiz.Grader {
+	return &quiz.Grader{Timeout: opts.codeTimeout, Memory: int64(opts.codeMemory) << 20}
+}
+
+// name identifies the quiz in the history of a user
+func (opts *quizOptions) name() string {
+	if opts.generate != "" {
+		return generatedQuizName
+	}
+	return filepath.Base(opts.quizPath)
+}
+
+// picked checks if the questions are picked at random, by sampling or generating them
+func (opts *quizOptions) picked() bool {
+	return opts.count > 0 || opts.generate != ""
+}
+
+// load reads the quiz along with the default matcher for its answers. The
+// questions are sampled with rng if a count is given
+func (opts *quizOptions) load(rng *rand.Rand) (*quiz.Quiz, quiz.Matcher, error) {
+	matcher, err := quiz.ParseMatcher(opts.match)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.penalty < 0 {
+		return nil, nil, fmt.Errorf("invalid penalty %v, it must not be negative", opts.penalty)
+	}
+	if opts.codeTimeout <= 0 {
+		return nil, nil, fmt.Errorf("invalid code timeout %v, it must be positive", opts.codeTimeout)
+	}
+	if minMemory := quiz.MinCodeMemory >> 20; opts.codeMemory < minMemory {
+		return nil, nil, fmt.Errorf("invalid code memory %d, the tests need at least %dMB", opts.codeMemory, minMemory)
+	}
+	var q *quiz.Quiz
+	if opts.generate != "" {
+		q, err = generate(opts.generate, rng)
+	} else if q, err = quiz.Load(opts.quizPath, opts.format); err != nil {
+		err = fmt.Errorf("failed to load questions from %s: %v", opts.quizPath, err)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.lang != "" {
+		if q, err = q.Translate(opts.lang); err != nil {
+			return nil, nil, fmt.Errorf("failed to translate %s: %v", opts.name(), err)
+		}
+	}
+
+	if opts.categories != "" || opts.tags != "" {
+		q = q.Filter(splitList(opts.categories), splitList(opts.tags))
+		if len(q.Questions) == 0 {
+			return nil, nil, fmt.Errorf("no questions in %s match the category and tag filters", opts.name())
+		}
+	}
+	if opts.count > 0 {
+		q = q.Sample(opts.count, rng)
+	}
+	return q, matcher, nil
+}
+
+// generate makes arithmetic questions from a spec of the -generate flag
+func generate(spec string, rng *rand.Rand) (*quiz.Quiz, error) {
+	if spec == generateDefault {
+		spec = ""
+	}
+	source, err := quiz.ParseArithmetic(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid arithmetic questions %q: %v", spec, err)
+	}
+	source.Rand = rng
+	return quiz.FromSource(source)
+}
+
+// splitList splits a comma separated flag value, ignoring empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// commands are the subcommands of the quiz. Without a command, a quiz is started
+var commands = map[string]func(args []string){
+	"history": historyCommand,
+	"import":  importCommand,
+	"lint":    lintCommand,
+	"resume":  resumeCommand,
+	"serve":   serveCommand,
+	"host":    hostCommand,
+	"verify":  verifyCommand,
+}
+
+func main() {
+	if len(os.Args) > 1 {
+		if command, found := commands[os.Args[1]]; found {
+			command(os.Args[2:])
+			return
+		}
+	}
+	flag.Usage = usage
+	runQuiz(os.Args[1:], nil)
+}
+
+// runQuiz runs a quiz with the given command line arguments. A saved session is
+// resumed with the arguments it was started with
+func runQuiz(args []string, saved *savedSession) {
+	var user, dbPath, mode, sessionPath string
+	var fullScreen, showScore bool
+	var hintCost float64
+	var reportPath, reportFmt string
+	var proctorKey, resultPath string
+	var opts quizOptions
+
+	opts.register(flag.CommandLine)
+	flag.StringVar(&reportPath, "report", "", "Export a detailed report of the quiz to this file")
+	flag.StringVar(&reportFmt, "report-format", "", "The format of the exported report (json, csv or md). Detected from the file extension by default")
+	flag.StringVar(&user, "user", "", "Record the attempt in the quiz history of this user")
+	flag.StringVar(&dbPath, "db", defaultDbPath, "The database where the quiz history is kept")
+	flag.StringVar(&mode, "mode", modeTest, "The quiz mode: test, study to review the questions which are due with spaced repetition, or adaptive to follow the difficulty of the questions to the skill of the user. Study requires a user")
+	flag.StringVar(&sessionPath, "session", defaultSessionPath, "The file where the quiz is saved when it is paused, to be continued with the resume command")
+	flag.Float64Var(&hintCost, "hint-cost", defaultHintCost, "The share of its points a question loses when its hint is revealed, from 0 to 1")
+	flag.BoolVar(&fullScreen, "tui", false, "Show the quiz full screen with a countdown, the progress and a history of the responses. Falls back to plain prompts when not run in a terminal")
+	flag.BoolVar(&showScore, "show-score", false, "Show the score while the quiz is running in full screen")
+	flag.StringVar(&proctorKey, "proctor-key", "", "Proctor the quiz with this key, an HMAC secret or an ed25519 private key in PEM form: log each response, pause and loss of focus, and sign the result. Requires a user")
+	flag.StringVar(&resultPath, "result", defaultResultPath, "The file where the signed result of a proctored quiz is saved, to be checked with the verify command")
+	//goland:noinspection GoUnhandledErrorResult
+	flag.CommandLine.Parse(args)
+	if saved != nil {
+		opts.seed, sessionPath = saved.Seed, saved.path
+	}
+
+	if hintCost < 0 || hintCost > 1 {
+		log.Fatalf("Invalid hint cost %v, it must be between 0 and 1\n", hintCost)
+	}
+
+	rng, seed := quiz.NewRand(opts.seed)
+	q, matcher, err := opts.load(rng)
+	if err != nil {
+		log.Fatal(err)
+	}
+	records := q.Records()
+
+	var key *quiz.Key
+	var events *quiz.EventLog
+	if proctorKey != "" {
+		if user == "" {
+			log.Fatal("A user is required to proctor the quiz")
+		}
+		if key, err = loadKey(proctorKey); err != nil {
+			log.Fatal(err)
+		}
+		if !key.CanSign() {
+			log.Fatal("A public key can't sign the result, the proctor key must be a private key or an HMAC secret")
+		}
+		if saved == nil {
+			events = quiz.NewEventLog(nil)
+			events.Add(quiz.EventStart, 0, "")
+		} else {
+			if err := saved.verify(key); err != nil {
+				log.Fatalf("Failed to resume the proctored quiz: %v\n", err)
+			}
+			events = quiz.NewEventLog(saved.Events)
+			events.Add(quiz.EventResume, 0, "")
+		}
+	}
+
+	quizName := opts.name()
+	quizDuration := opts.duration()
+	switch mode {
+	case modeTest, modeAdaptive:
+	case modeStudy:
+		if user == "" {
+			log.Fatal("A user is required to study")
+		}
+		if saved != nil {
+			break
+		}
+		var nextDue time.Time
+		if records, nextDue, err = studyQuestions(dbPath, user, quizName, records); err != nil {
+			log.Fatal(err)
+		}
+		if len(records) == 0 {
+			fmt.Printf("Nothing is due for review. Come back on %s\n", nextDue.Local().Format("2006-01-02 15:04"))
+			return
+		}
+		//study sessions are untimed unless asked otherwise
+		if !flagPassed("duration") {
+			quizDuration = 0
+		}
+	default:
+		log.Fatalf("Unknown quiz mode %q\n", mode)
+	}
+
+	if saved != nil {
+		if records, err = saved.State.Restore(q.Questions); err != nil {
+			log.Fatalf("Failed to resume the quiz: %v\n", err)
+		}
+		quizDuration = saved.State.TimeLeft
+	} else if opts.shuffle {
+		quiz.Shuffle(records, rng)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pause, stopPause := notifyPause()
+	defer stopPause()
+
+	var input io.Reader = os.Stdin
+	var output io.Writer = os.Stdout
+	var screen *tui.Screen
+	if fullScreen && tui.IsTerminal(os.Stdin, os.Stdout) {
+		opt := tui.Option{Matcher: matcher, Penalty: opts.penalty, ShowScore: showScore, Interrupt: cancel, Log: events}
+		if screen, err = tui.Open(os.Stdin, os.Stdout, opt); err != nil {
+			log.Fatal(err)
+		}
+		//goland:noinspection GoUnhandledErrorResult
+		defer screen.Close()
+		input, output, pause = screen, screen, screen.Pause()
+	}
+
+	session := quiz.Session{
+		Records:     records,
+		TimeLimit:   quizDuration,
+		PerQuestion: opts.perQuestion,
+		Input:       quiz.NewInputReader(ctx, input),
+		Output:      output,
+		Pause:       pause,
+		HintCost:    hintCost,
+		Grader:      opts.grader(),
+		Log:         events,
+		//markdown questions are only highlighted in a terminal, unless colors are turned off
+		Color: term.IsTerminal(int(os.Stdout.Fd())) && os.Getenv("NO_COLOR") == "",
+	}
+	if mode == modeAdaptive {
+		adaptive := quiz.NewAdaptive(matcher)
+		adaptive.Replay(records)
+		session.Order = adaptive
+	}
+
+	if saved == nil {
+		fmt.Fprintf(output, "Welcome to Quizbot. Please answer to the best of your knowledge\n")
+	}
+	if session.Order == nil {
+		fmt.Fprintf(output, "Type %s to answer a question later, %s for a hint or %s to change the previous answer\n",
+			quiz.SkipCommand, quiz.HintCommand, quiz.BackCommand)
+	} else {
+		//the questions of an adaptive quiz are picked by the responses, which can't be skipped or changed
+		fmt.Fprintf(output, "Type %s for a hint\n", quiz.HintCommand)
+	}
+	fmt.Fprintf(output, "Type %s or press Ctrl-Z to pause the quiz and continue it later\n", quiz.PauseCommand)
+	fmt.Fprintf(output, "Press enter to start...\n")
+	if _, err := session.Input.ReadLine(ctx); err != nil {
+		return
+	}
+
+	if screen == nil {
+		for range session.Start(ctx) {
+		}
+	} else {
+		for range screen.Start(ctx, &session) {
+		}
+		if !session.Paused() {
+			screen.Review(ctx)
+		}
+		//goland:noinspection GoUnhandledErrorResult
+		screen.Close()
+	}
+
+	if session.Paused() {
+		paused := savedSession{Args: args, Seed: seed, Time: time.Now(), State: session.State(), Events: events.Events()}
+		if key != nil {
+			if err := paused.sign(key); err != nil {
+				log.Fatalf("Failed to sign the paused quiz: %v\n", err)
+			}
+		}
+		if err := saveSession(sessionPath, &paused); err != nil {
+			log.Fatalf("Failed to save the paused quiz: %v\n", err)
+		}
+		fmt.Printf("\nThe quiz was paused and saved to %s. Continue it with: %s resume -session %s\n",
+			sessionPath, filepath.Base(os.Args[0]), sessionPath)
+		return
+	}
+	if saved != nil {
+		//goland:noinspection GoUnhandledErrorResult
+		os.Remove(saved.path)
+	}
+
+	report := quiz.NewReport(records, matcher, opts.penalty)
+	report.Seed = seed
+	if report.Total == 0 {
+		fmt.Printf("\nThere were no questions in the quiz\n")
+		return
+	}
+	fmt.Println()
+	//goland:noinspection GoUnhandledErrorResult
+	report.Print(os.Stdout)
+	fmt.Printf("\nThanks for taking the quiz. You scored %d/%d = %.1f%%\n", report.Correct, report.Total, report.Percent())
+	fmt.Printf("Weighted score: %s points = %.1f%%\n", report.Score(), report.WeightedPercent())
+	if skill := report.SkillLevel(); skill != "" {
+		fmt.Printf("Estimated skill level: %s\n", skill)
+	}
+	fmt.Printf("Seed: %d (replay this session with -seed %d)\n", seed, seed)
+
+	if reportPath != "" {
+		if err := exportReport(report, reportPath, reportFmt); err != nil {
+			log.Fatalf("Failed to export the report: %v\n", err)
+		}
+		fmt.Printf("The report was saved to %s\n", reportPath)
+	}
+
+	if key != nil {
+		events.Add(quiz.EventEnd, 0, "")
+		logged := events.Events()
+		result := quiz.Result{
+			Quiz:     quizName,
+			User:     user,
+			Seed:     seed,
+			Started:  logged[0].Time,
+			Finished: logged[len(logged)-1].Time,
+			Report:   report,
+			Events:   logged,
+		}
+		if err := saveResult(resultPath, &result, key); err != nil {
+			log.Fatalf("Failed to save the result: %v\n", err)
+		}
+		fmt.Printf("The signed result was saved to %s\n", resultPath)
+	}
+
+	if mode == modeStudy {
+		if err := updateReviews(dbPath, user, quizName, records, matcher); err != nil {
+			log.Fatalf("Failed to save the review schedule: %v\n", err)
+		}
+	} else if user != "" {
+		attempt := store.Attempt{User: user, Quiz: quizName, Time: time.Now(), Report: report}
+		if err := saveAttempt(dbPath, &attempt); err != nil {
+			log.Fatalf("Failed to save the attempt: %v\n", err)
+		}
+	}
+}
+
+func flagPassed(name string) bool {
+	passed := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			passed = true
+		}
+	})
+	return passed
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [command] [flags]\n\nCommands:\n", filepath.Base(os.Args[0]))
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %s\n", name)
+	}
+	fmt.Fprintf(out, "\nWithout a command, a quiz is started with the flags:\n")
+	flag.PrintDefaults()
+}
+
+func exportReport(report *quiz.Report, path, format string) error {
+	format, err := quiz.ReportFormat(path, format)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file %s: %v", path, err)
+	}
+
+	//goland:noinspection GoUnhandledErrorResult
+	defer file.Close()
+	return report.Export(file, format)
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+// notifyPause does nothing where there is no Ctrl-Z signal. The quiz can still
+// be paused with the :pause command
+func notifyPause() (<-chan struct{}, func()) {
+	return nil, func() {}
+}
@@ -0,0 +1,30 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyPause turns Ctrl-Z into a request to pause the quiz rather than suspending
+// it, since the time limit would keep running while the quiz is suspended
+func notifyPause() (<-chan struct{}, func()) {
+	signals := make(chan os.Signal, 1)
+	pause := make(chan struct{}, 1)
+	signal.Notify(signals, syscall.SIGTSTP)
+	go func() {
+		for range signals {
+			select {
+			case pause <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return pause, func() {
+		signal.Stop(signals)
+		close(signals)
+	}
+}
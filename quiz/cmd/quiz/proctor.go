@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"gophercises.com/quiz"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const defaultResultPath = "quiz-result.json"
+
+func loadKey(path string) (*quiz.Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the key: %v", err)
+	}
+	key, err := quiz.ParseKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key in %s: %v", path, err)
+	}
+	return key, nil
+}
+
+// sign signs a paused proctored quiz, so that its responses and events can't be
+// changed before it is resumed
+func (saved *savedSession) sign(key *quiz.Key) error {
+	saved.Signature = nil
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	saved.Signature, err = key.Sign(data)
+	return err
+}
+
+func (saved *savedSession) verify(key *quiz.Key) error {
+	unsigned := *saved
+	unsigned.Signature = nil
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return err
+	}
+	return key.Verify(data, saved.Signature)
+}
+
+// saveResult signs the result of a proctored quiz and saves it
+func saveResult(path string, result *quiz.Result, key *quiz.Key) error {
+	data, err := quiz.SignResult(result, key)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// verifyCommand checks the signature of the result of a proctored quiz, and
+// shows the score along with the events which need a closer look
+func verifyCommand(args []string) {
+	var (
+		keyPath    string
+		showEvents bool
+		flags      = flag.NewFlagSet("verify", flag.ExitOnError)
+	)
+	flags.StringVar(&keyPath, "key", "", "The key the result was signed with: the HMAC secret, or the ed25519 public or private key in PEM form")
+	flags.BoolVar(&showEvents, "events", false, "Show every event of the quiz")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: verify -key file [flags] [result file]\n")
+		flags.PrintDefaults()
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	flags.Parse(args)
+
+	if keyPath == "" {
+		log.Fatal("A key is required to verify the result")
+	}
+	resultPath := defaultResultPath
+	if flags.NArg() > 0 {
+		resultPath = flags.Arg(0)
+	}
+	key, err := loadKey(keyPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	data, err := os.ReadFile(resultPath)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Fatalf("No result found in %s\n", resultPath)
+	} else if err != nil {
+		log.Fatal(err)
+	}
+	result, err := quiz.VerifyResult(data, key)
+	if err != nil {
+		log.Fatalf("The result in %s is NOT valid: %v\n", resultPath, err)
+	}
+
+	fmt.Printf("The result in %s is valid\n\n", resultPath)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Quiz:\t%s\n", result.Quiz)
+	fmt.Fprintf(w, "User:\t%s\n", result.User)
+	fmt.Fprintf(w, "Started:\t%s\n", result.Started.Local().Format(time.RFC1123))
+	fmt.Fprintf(w, "Finished:\t%s\n", result.Finished.Local().Format(time.RFC1123))
+	if report := result.Report; report != nil {
+		fmt.Fprintf(w, "Score:\t%d/%d = %.1f%%\n", report.Correct, report.Total, report.Percent())
+		fmt.Fprintf(w, "Weighted score:\t%s points = %.1f%%\n", report.Score(), report.WeightedPercent())
+	}
+	counts := make(map[string]int)
+	for _, event := range result.Events {
+		counts[event.Kind]++
+	}
+	fmt.Fprintf(w, "Pauses:\t%d\n", counts[quiz.EventPause])
+	fmt.Fprintf(w, "Focus lost:\t%d\n", counts[quiz.EventFocusLost])
+	fmt.Fprintf(w, "Timeouts:\t%d\n", counts[quiz.EventTimeout])
+	//goland:noinspection GoUnhandledErrorResult
+	w.Flush()
+
+	if !showEvents {
+		return
+	}
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tEVENT\tQUESTION\tTEXT")
+	for _, event := range result.Events {
+		question := "-"
+		if event.Question > 0 {
+			question = fmt.Sprintf("#%d", event.Question)
+		}
+		text := ""
+		if event.Text != "" {
+			text = strconv.Quote(event.Text)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", event.Time.Local().Format("15:04:05.000"), event.Kind, question, text)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	w.Flush()
+}
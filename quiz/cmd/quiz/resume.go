@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"gophercises.com/quiz"
+	"log"
+	"os"
+	"time"
+)
+
+const defaultSessionPath = "quiz-session.json"
+
+// savedSession is a paused quiz. It is resumed by loading the quiz again with
+// the same arguments and seed, and then restoring the state of its records. A
+// proctored quiz also keeps its events, and is signed
+type savedSession struct {
+	Args      []string           `json:"args"`
+	Seed      int64              `json:"seed"`
+	Time      time.Time          `json:"time"`
+	State     *quiz.SessionState `json:"state"`
+	Events    []quiz.Event       `json:"events,omitempty"`
+	Signature *quiz.Signature    `json:"signature,omitempty"`
+	path      string
+}
+
+func saveSession(path string, saved *savedSession) error {
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func loadSession(path string) (*savedSession, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no paused quiz found in %s", path)
+	} else if err != nil {
+		return nil, err
+	}
+	saved := savedSession{path: path}
+	if err := json.Unmarshal(data, &saved); err != nil || saved.State == nil {
+		return nil, fmt.Errorf("invalid paused quiz in %s", path)
+	}
+	return &saved, nil
+}
+
+// resumeCommand continues a quiz which was paused with Ctrl-Z or the :pause command
+func resumeCommand(args []string) {
+	var (
+		sessionPath string
+		flags       = flag.NewFlagSet("resume", flag.ExitOnError)
+	)
+	flags.StringVar(&sessionPath, "session", defaultSessionPath, "The file where the quiz was saved when it was paused")
+	//goland:noinspection GoUnhandledErrorResult
+	flags.Parse(args)
+
+	saved, err := loadSession(sessionPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Resuming the quiz paused on %s\n", saved.Time.Local().Format("2006-01-02 15:04"))
+	runQuiz(saved.Args, saved)
+}
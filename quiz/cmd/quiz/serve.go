@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"gophercises.com/quiz"
+	quizhttp "gophercises.com/quiz/http"
+	"html/template"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+const defaultPort = 3000
+
+// handlerOption configures the web handlers with the quiz flags. The questions
+// which were picked with the seed are the same in every session, so the sessions
+// are seeded with it as well, which makes the seed they report replay them
+func (opts *quizOptions) handlerOption(tpl *template.Template, matcher quiz.Matcher, seed int64) quizhttp.HandlerOption {
+	option := quizhttp.HandlerOption{
+		Tpl:         tpl,
+		Matcher:     matcher,
+		Duration:    opts.duration(),
+		PerQuestion: opts.perQuestion,
+		Shuffle:     opts.shuffle,
+		Penalty:     opts.penalty,
+		Seed:        opts.seed,
+	}
+	if opts.picked() {
+		option.Seed = seed
+	}
+	return option
+}
+
+// serveCommand serves the quiz in the browser
+func serveCommand(args []string) {
+	var (
+		opts  quizOptions
+		port  int
+		flags = flag.NewFlagSet("serve", flag.ExitOnError)
+	)
+	opts.register(flags)
+	flags.IntVar(&port, "port", defaultPort, "the port to start the quiz web application on")
+	//goland:noinspection GoUnhandledErrorResult
+	flags.Parse(args)
+
+	rng, seed := quiz.NewRand(opts.seed)
+	q, matcher, err := opts.load(rng)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(q.Questions) == 0 {
+		log.Fatalf("There are no questions in %s\n", opts.name())
+	}
+
+	tpl := template.Must(template.ParseGlob("web/templates/*.gohtml"))
+	handler, err := quizhttp.NewQuizHandler(q, opts.handlerOption(tpl, matcher, seed))
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := &http.Server{Addr: fmt.Sprintf(":%d", port), Handler: handler}
+	go func() {
+		log.Printf("Starting the server on port %d\n", port)
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+		log.Println("Shutting down server")
+	}()
+
+	exit := make(chan os.Signal, 1)
+	signal.Notify(exit, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	<-exit
+
+	log.Println("Received shutdown signal")
+	if err := srv.Shutdown(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// hostCommand hosts a live quiz which players join from their browser
+func hostCommand(args []string) {
+	var (
+		opts  quizOptions
+		port  int
+		flags = flag.NewFlagSet("host", flag.ExitOnError)
+	)
+	opts.register(flags)
+	flags.IntVar(&port, "port", defaultPort, "the port to start the live quiz on")
+	//goland:noinspection GoUnhandledErrorResult
+	flags.Parse(args)
+
+	rng, seed := quiz.NewRand(opts.seed)
+	q, matcher, err := opts.load(rng)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(q.Questions) == 0 {
+		log.Fatalf("There are no questions in %s\n", opts.name())
+	}
+
+	tpl := template.Must(template.ParseGlob("web/templates/*.gohtml"))
+	game, err := quizhttp.NewLiveGame(q, opts.handlerOption(tpl, matcher, seed))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go game.Run(ctx)
+
+	//the event streams only end with their requests, so they are tied to ctx to let the server shut down
+	srv := &http.Server{
+		Addr:        fmt.Sprintf(":%d", port),
+		Handler:     game,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		log.Printf("Starting the server on port %d\n", port)
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+		log.Println("Shutting down server")
+	}()
+
+	fmt.Printf("Players join at http://localhost:%d with the code %s\n", port, game.Code())
+	fmt.Printf("Follow the game at http://localhost:%d/host?token=%s\n", port, game.HostToken())
+	if shuffleSeed := game.Seed(); shuffleSeed != 0 {
+		fmt.Printf("The questions were shuffled with the seed %d\n", shuffleSeed)
+	} else if opts.picked() {
+		fmt.Printf("The questions were picked with the seed %d\n", seed)
+	}
+	fmt.Printf("Press enter to start the game...\n")
+	go func() {
+		if _, err := quiz.NewInputReader(ctx, os.Stdin).ReadLine(ctx); err == nil {
+			game.Begin()
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Received shutdown signal")
+	if err := srv.Shutdown(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+}
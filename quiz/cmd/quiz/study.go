@@ -0,0 +1,48 @@
+package main
+
+import (
+	"gophercises.com/quiz"
+	"gophercises.com/quiz/store"
+	"time"
+)
+
+const (
+	modeTest     = "test"
+	modeStudy    = "study"
+	modeAdaptive = "adaptive"
+)
+
+// studyQuestions loads the review states of the user and returns the questions due for review
+func studyQuestions(dbPath, user, quizName string, records []quiz.Record) ([]quiz.Record, time.Time, error) {
+	db, err := store.Open(dbPath)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	//goland:noinspection GoUnhandledErrorResult
+	defer db.Close()
+	states, err := db.Reviews(user, quizName, records)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	due, nextDue := quiz.DueQuestions(records, states, time.Now())
+	return due, nextDue, nil
+}
+
+// updateReviews reschedules every question which was asked during the study session
+func updateReviews(dbPath, user, quizName string, records []quiz.Record, matcher quiz.Matcher) error {
+	db, err := store.Open(dbPath)
+	if err != nil {
+		return err
+	}
+
+	//goland:noinspection GoUnhandledErrorResult
+	defer db.Close()
+	states, err := db.Reviews(user, quizName, records)
+	if err != nil {
+		return err
+	}
+
+	reviewed := quiz.ReviewQuestions(records, states, matcher, time.Now())
+	return db.SaveReviews(user, quizName, reviewed)
+}
@@ -0,0 +1,305 @@
+package quiz
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	defaultCodeTimeout  = 10 * time.Second
+	defaultCodeMemory   = 256 << 20
+	defaultBuildTimeout = 2 * time.Minute
+	// MinCodeMemory is the least memory the Go runtime needs to start the tests
+	MinCodeMemory = 128 << 20
+	// maxCodeOutput is how much of the output of a failed build is kept
+	maxCodeOutput = 4 << 10
+
+	codePackage     = "solution"
+	codeModule      = "solution"
+	codeBinary      = "solution.test"
+	codeFile        = "solution.go"
+	codeTestFile    = "solution_test.go"
+	codeHarnessFile = "harness_test.go"
+)
+
+// CodeTests are the hidden tests of a question which is answered with Go code.
+// The response is built into a package along with the tests, and earns an equal
+// share of the points for each test which passes
+type CodeTests struct {
+	// Source is a Go test file. The package clause may be left out
+	Source string
+	// Names are the test functions of the source
+	Names []string
+}
+
+// newCodeTests finds the test functions of the source. A TestMain function is
+// not allowed, since the grader needs its own
+func newCodeTests(source string) (*CodeTests, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), codeTestFile, setPackage(source, codePackage), 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tests: %v", err)
+	}
+	tests := &CodeTests{Source: source}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || !isTestName(fn.Name.Name) {
+			continue
+		}
+		if fn.Name.Name == "TestMain" {
+			return nil, errors.New("the tests may not have a TestMain function")
+		}
+		tests.Names = append(tests.Names, fn.Name.Name)
+	}
+	if len(tests.Names) == 0 {
+		return nil, errors.New("the tests have no Test functions")
+	}
+	return tests, nil
+}
+
+// isTestName checks the name the same way as go test, eg. Test and TestAdd are
+// tests but Testify is not
+func isTestName(name string) bool {
+	if !strings.HasPrefix(name, "Test") {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(name[len("Test"):])
+	return r == utf8.RuneError || !unicode.IsLower(r)
+}
+
+// IsCode checks if the question is answered with Go code. See Grader
+func (question *Question) IsCode() bool {
+	return question.Code != nil
+}
+
+// packageName is the name in the package clause of the source, if it has one
+func packageName(source string) (string, bool) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", source, parser.PackageClauseOnly)
+	if err != nil {
+		return "", false
+	}
+	return file.Name.Name, true
+}
+
+// setPackage replaces the package clause of the source, or adds one if it has none
+func setPackage(source, name string) string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", source, parser.PackageClauseOnly)
+	if err != nil {
+		//on the same line, so that the lines in the errors of the build still match
+		return "package " + name + "; " + source
+	}
+	//the positions of a file start at 1
+	start, end := int(file.Package)-1, int(file.Name.End())-1
+	return source[:start] + "package " + name + source[end:]
+}
+
+// Grader runs the hidden tests of code questions against the responses. The
+// tests are built with the go command, and then each test is run on its own in a
+// subprocess, in a temporary directory, with a minimal environment and limits on
+// its time and memory. This keeps mistakes such as an endless loop from hanging
+// the quiz, but it is not meant to contain code which was written to do harm.
+// Code questions are only asked in the terminal for that reason
+type Grader struct {
+	// Go is the go command, found on the PATH by default
+	Go string
+	// Timeout is the time limit of each test, 10s by default
+	Timeout time.Duration
+	// Memory is the memory limit of each test in bytes, 256MB by default. It is only enforced on unix
+	Memory int64
+}
+
+// Grade is the result of running the tests of a code question
+type Grade struct {
+	Passed int
+	Total  int
+	// Failed are the names of the tests which failed
+	Failed []string
+	// Output is the output of a build which failed, which the learner needs to fix the code
+	Output string
+}
+
+// Grade builds the code with the tests and runs them. Code which does not build
+// passes none of the tests, the error is only for a failure to run the tests at all
+func (grader *Grader) Grade(ctx context.Context, tests *CodeTests, code string) (Grade, error) {
+	grade := Grade{Total: len(tests.Names)}
+	dir, err := os.MkdirTemp("", "quiz-code-")
+	if err != nil {
+		return grade, err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer os.RemoveAll(dir)
+
+	pkg, found := packageName(code)
+	if !found {
+		pkg, code = codePackage, setPackage(code, codePackage)
+	}
+	//the harness only reports success once all the tests ran, so that code which
+	//exits early can't pass for a test
+	nonce, err := newNonce()
+	if err != nil {
+		return grade, err
+	}
+	harness := fmt.Sprintf(harnessSource, pkg, nonce)
+	files := map[string]string{
+		codeFile:        code,
+		codeTestFile:    setPackage(tests.Source, pkg),
+		codeHarnessFile: harness,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			return grade, err
+		}
+	}
+
+	buildCtx, cancel := context.WithTimeout(ctx, defaultBuildTimeout)
+	defer cancel()
+	if output, err := grader.goCommand(buildCtx, dir, "mod", "init", codeModule); err != nil {
+		return grade, fmt.Errorf("go mod init failed: %v: %s", err, output)
+	}
+	output, err := grader.goCommand(buildCtx, dir, "test", "-c", "-vet=off", "-o", codeBinary)
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr) && buildCtx.Err() == nil:
+		grade.Failed = tests.Names
+		grade.Output = limitOutput(strings.ReplaceAll(output, dir+string(filepath.Separator), ""))
+		return grade, nil
+	case err != nil:
+		return grade, fmt.Errorf("failed to build the tests: %v", err)
+	}
+	//the tests only need the binary, and can't read the hidden tests
+	for name := range files {
+		//goland:noinspection GoUnhandledErrorResult
+		os.Remove(filepath.Join(dir, name))
+	}
+
+	for _, name := range tests.Names {
+		if err := ctx.Err(); err != nil {
+			return grade, err
+		}
+		if grader.runTest(ctx, dir, name, nonce) {
+			grade.Passed++
+		} else {
+			grade.Failed = append(grade.Failed, name)
+		}
+	}
+	return grade, nil
+}
+
+const harnessSource = `package %s
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if code == 0 {
+		fmt.Println("%s")
+	}
+	os.Exit(code)
+}
+`
+
+func (grader *Grader) goCommand(ctx context.Context, dir string, args ...string) (string, error) {
+	command := grader.Go
+	if command == "" {
+		command = "go"
+	}
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Dir = dir
+	//the code may only use the standard library, and is built on its own even
+	//inside a workspace
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off", "GO111MODULE=on")
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+// runTest runs a single test in the sandbox. It passes if the harness reports
+// that it ran to the end
+func (grader *Grader) runTest(ctx context.Context, dir, name, nonce string) bool {
+	timeout := grader.Timeout
+	if timeout <= 0 {
+		timeout = defaultCodeTimeout
+	}
+	memory := grader.Memory
+	if memory <= 0 {
+		memory = defaultCodeMemory
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	binary := filepath.Join(dir, codeBinary)
+	cmd := sandboxCommand(binary, []string{"-test.run", "^" + name + "$", "-test.count", "1"}, memory, timeout)
+	cmd.Dir = dir
+	cmd.Env = []string{"HOME=" + dir, "TMPDIR=" + dir, fmt.Sprintf("GOMEMLIMIT=%d", memory/2)}
+	output := &nonceWriter{nonce: nonce}
+	cmd.Stdout = output
+	if err := cmd.Start(); err != nil {
+		return false
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	select {
+	case err := <-done:
+		return err == nil && output.found
+	case <-ctx.Done():
+		killSandbox(cmd)
+		<-done
+		return false
+	}
+}
+
+func newNonce() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func limitOutput(output string) string {
+	output = strings.TrimSpace(output)
+	if len(output) > maxCodeOutput {
+		output = output[:maxCodeOutput] + "\n..."
+	}
+	return output
+}
+
+// nonceWriter looks for the nonce in the output of a test, which is otherwise
+// dropped, so that a test which prints without end can't use up the memory of the quiz
+type nonceWriter struct {
+	nonce string
+	tail  []byte
+	found bool
+}
+
+func (w *nonceWriter) Write(p []byte) (int, error) {
+	if w.found {
+		return len(p), nil
+	}
+	//the nonce may be split between two writes
+	data := append(w.tail, p...)
+	w.found = bytes.Contains(data, []byte(w.nonce))
+	if keep := len(w.nonce) - 1; len(data) > keep {
+		data = data[len(data)-keep:]
+	}
+	w.tail = append(w.tail[:0], data...)
+	return len(p), nil
+}
@@ -0,0 +1,146 @@
+package quiz
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+const addTests = `import "testing"
+
+func TestAdd(t *testing.T) {
+	if Add(1, 2) != 3 {
+		t.Error("Add(1, 2) != 3")
+	}
+}
+
+func TestNegative(t *testing.T) {
+	if Add(-1, -2) != -3 {
+		t.Error("Add(-1, -2) != -3")
+	}
+}
+
+func Testify() {}
+`
+
+func TestQuestionSources_code(t *testing.T) {
+	content := "- question: Write func Add(a, b int) int\n  tests: |\n" + indentLines(addTests, "    ")
+	source, _ := NewSource(formatYAML, strings.NewReader(content))
+	questions, err := source.Questions()
+	if err != nil {
+		t.Fatalf("Questions() received an error: %v", err)
+	}
+	if !questions[0].IsCode() || strings.Join(questions[0].Code.Names, ",") != "TestAdd,TestNegative" {
+		t.Errorf("Questions(): want a code question with 2 tests, got %+v", questions[0].Code)
+	}
+
+	for _, content := range []string{
+		`[{"question": "Add", "tests": "func helper() {}"}]`,
+		`[{"question": "Add", "tests": "func TestAdd(t *testing.T) {"}]`,
+		`[{"question": "Add", "tests": "func TestMain(m *testing.M) {}\nfunc TestAdd(t *testing.T) {}"}]`,
+		`[{"question": "Add", "options": ["a", "b"], "answer": "a", "tests": "func TestAdd(t *testing.T) {}"}]`,
+	} {
+		source, _ := NewSource(formatJSON, strings.NewReader(content))
+		if _, err := source.Questions(); err == nil {
+			t.Errorf("Questions(): expected an error for %s", content)
+		}
+	}
+}
+
+func indentLines(text, prefix string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for idx := range lines {
+		lines[idx] = prefix + lines[idx]
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func TestSetPackage(t *testing.T) {
+	tests := map[string]string{
+		"func Add() {}":                         "package solution; func Add() {}",
+		"package main\n\nfunc Add() {}":         "package solution\n\nfunc Add() {}",
+		"// Add\npackage  other\nfunc Add() {}": "// Add\npackage solution\nfunc Add() {}",
+	}
+	for source, want := range tests {
+		if got := setPackage(source, "solution"); got != want {
+			t.Errorf("setPackage(%q): want %q, got %q", source, want, got)
+		}
+	}
+}
+
+func TestRecord_Credit_code(t *testing.T) {
+	record := Record{Question: Question{Code: &CodeTests{Names: []string{"TestA", "TestB", "TestC", "TestD"}}}, Answered: true, Passed: 3}
+	if got := record.Credit(nil); got != 0.75 {
+		t.Errorf("Credit(): want 0.75 for 3 out of 4 tests, got %v", got)
+	}
+	if record.IsCorrect(nil) {
+		t.Errorf("IsCorrect(): want a failed test to make the response wrong")
+	}
+	if record.Passed = 4; !record.IsCorrect(nil) {
+		t.Errorf("IsCorrect(): want the response to be correct once all tests pass")
+	}
+}
+
+func TestGrader_Grade(t *testing.T) {
+	if testing.Short() {
+		t.Skip("building the tests takes too long in short mode")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("the go command is needed to grade code")
+	}
+	tests, err := newCodeTests(addTests)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grader := &Grader{Timeout: 2 * time.Second}
+	cases := []struct {
+		name, code string
+		passed     int
+		output     string
+	}{
+		{"correct", "func Add(a, b int) int { return a + b }", 2, ""},
+		{"partial", "func Add(a, b int) int {\n\tif a < 0 {\n\t\treturn 0\n\t}\n\treturn a + b\n}", 1, ""},
+		{"other package", "package main\n\nfunc Add(a, b int) int { return a + b }", 2, ""},
+		{"build error", "func Add(a, b int) int {\n\treturn a + c\n}", 0, "solution.go:2:"},
+		{"exit early", "import \"os\"\n\nfunc init() { os.Exit(0) }\n\nfunc Add(a, b int) int { return 0 }", 0, ""},
+		{"endless", "func Add(a, b int) int { for {} }", 0, ""},
+	}
+	for _, c := range cases {
+		grade, err := grader.Grade(context.Background(), tests, c.code)
+		if err != nil {
+			t.Errorf("%s: Grade() received an error: %v", c.name, err)
+			continue
+		}
+		if grade.Passed != c.passed || grade.Total != 2 || grade.Passed+len(grade.Failed) != 2 {
+			t.Errorf("%s: Grade(): want %d/2 tests to pass, got %+v", c.name, c.passed, grade)
+		}
+		if !strings.Contains(grade.Output, c.output) || (c.output == "") != (grade.Output == "") {
+			t.Errorf("%s: Grade(): want the output to contain %q, got %q", c.name, c.output, grade.Output)
+		}
+	}
+}
+
+func TestQuizSession_code(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	records := []Record{{Question: Question{Text: "Write Add", Code: &CodeTests{Names: []string{"TestAdd"}}}}}
+	input := strings.Join([]string{"func Add(a, b int) int {", SkipCommand, "}", EndCommand}, "\n")
+	session := newTestSession(ctx, strings.NewReader(input+"\n"), records)
+	//the tests can't be run without the go command, which leaves the response wrong
+	session.Grader = &Grader{Go: "no-such-go-command"}
+	for range session.Start(ctx) {
+	}
+
+	record := session.Records[0]
+	if want := "func Add(a, b int) int {\n:skip\n}"; !record.Answered || record.Response != want {
+		t.Errorf("Response: want the lines up to %s, got %q", EndCommand, record.Response)
+	}
+	if out := session.Output.(*bytes.Buffer).String(); !strings.Contains(out, "The tests could not be run") {
+		t.Errorf("output: want the failure to run the tests, got %q", out)
+	}
+}
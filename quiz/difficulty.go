@@ -0,0 +1,134 @@
+package quiz
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Difficulty is how hard a question is, from easy to hard
+type Difficulty int
+
+const (
+	DifficultyEasy Difficulty = iota + 1
+	DifficultyMedium
+	DifficultyHard
+)
+
+var difficultyNames = map[Difficulty]string{
+	DifficultyEasy:   "easy",
+	DifficultyMedium: "medium",
+	DifficultyHard:   "hard",
+}
+
+// ParseDifficulty reads a difficulty given either by name (easy, medium, hard) or by level (1-3)
+func ParseDifficulty(value string) (Difficulty, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if level, err := strconv.Atoi(value); err == nil && level >= int(DifficultyEasy) && level <= int(DifficultyHard) {
+		return Difficulty(level), nil
+	}
+	for difficulty, name := range difficultyNames {
+		if value == name {
+			return difficulty, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid difficulty %q, expected easy, medium or hard", value)
+}
+
+func (d Difficulty) String() string {
+	if name, found := difficultyNames[d]; found {
+		return name
+	}
+	return ""
+}
+
+// Level is the difficulty of the question. Questions without one count as medium
+func (question *Question) Level() Difficulty {
+	if question.Difficulty == 0 {
+		return DifficultyMedium
+	}
+	return question.Difficulty
+}
+
+// Adaptive is an Order which starts with medium questions and moves up a level
+// after each correct answer and down a level after each wrong or missing answer
+type Adaptive struct {
+	matcher Matcher
+	level   Difficulty
+}
+
+func NewAdaptive(matcher Matcher) *Adaptive {
+	return &Adaptive{matcher: matcher, level: DifficultyMedium}
+}
+
+// Next picks the first of the remaining questions which is closest to the
+// current level, preferring an easier question over a harder one
+func (adaptive *Adaptive) Next(remaining []Record, last *Record) int {
+	if last != nil {
+		if last.Answered && last.IsCorrect(adaptive.matcher) {
+			if adaptive.level < DifficultyHard {
+				adaptive.level++
+			}
+		} else if adaptive.level > DifficultyEasy {
+			adaptive.level--
+		}
+	}
+
+	best, bestDistance := 0, math.MaxInt
+	for idx := range remaining {
+		//an easier question is one step closer than a harder question of the same distance
+		distance := 2 * int(remaining[idx].Level()-adaptive.level)
+		if distance < 0 {
+			distance = -distance - 1
+		}
+		if distance < bestDistance {
+			best, bestDistance = idx, distance
+		}
+	}
+	return best
+}
+
+// Replay catches up with the records which were asked before a session was
+// paused, so that the resumed session carries on at the same level
+func (adaptive *Adaptive) Replay(records []Record) {
+	for idx := 1; idx < len(records) && records[idx].Asked; idx++ {
+		adaptive.Next(nil, &records[idx-1])
+	}
+}
+
+// EstimateSkill estimates the skill level, from 1 (easy) to 3 (hard), from the
+// difficulty of the questions which were asked. A correct answer counts as
+// half a level above the question and a wrong one as half a level below it.
+// It returns 0 if no question was asked
+func EstimateSkill(records []Record, matcher Matcher) float64 {
+	var total float64
+	var asked int
+	for idx := range records {
+		record := &records[idx]
+		if !record.Asked {
+			continue
+		}
+		estimate := float64(record.Level()) - 0.5
+		if record.Answered && record.IsCorrect(matcher) {
+			estimate += 1
+		}
+		total += estimate
+		asked++
+	}
+	if asked == 0 {
+		return 0
+	}
+	return math.Max(float64(DifficultyEasy), math.Min(float64(DifficultyHard), total/float64(asked)))
+}
+
+// SkillLevel describes an estimate of EstimateSkill
+func SkillLevel(skill float64) string {
+	switch {
+	case skill < 1.5:
+		return "beginner"
+	case skill < 2.5:
+		return "intermediate"
+	}
+	return "advanced"
+}
@@ -0,0 +1,104 @@
+package quiz
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseDifficulty(t *testing.T) {
+	for value, want := range map[string]Difficulty{"easy": DifficultyEasy, " Medium ": DifficultyMedium, "3": DifficultyHard} {
+		if got, err := ParseDifficulty(value); err != nil || got != want {
+			t.Errorf("ParseDifficulty(%q): want %s, got %s (%v)", value, want, got, err)
+		}
+	}
+	for _, value := range []string{"", "0", "4", "extreme"} {
+		if _, err := ParseDifficulty(value); err == nil {
+			t.Errorf("ParseDifficulty(%q): expected an error", value)
+		}
+	}
+}
+
+func TestAdaptive(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	exact, _ := ParseMatcher(matchExact)
+	records := []Record{
+		{Question: Question{Text: "easy1", Answer: "a", Difficulty: DifficultyEasy}},
+		{Question: Question{Text: "easy2", Answer: "a", Difficulty: DifficultyEasy}},
+		{Question: Question{Text: "medium1", Answer: "a", Difficulty: DifficultyMedium}},
+		{Question: Question{Text: "medium2", Answer: "a", Difficulty: DifficultyMedium}},
+		{Question: Question{Text: "hard1", Answer: "a", Difficulty: DifficultyHard}},
+		{Question: Question{Text: "hard2", Answer: "a", Difficulty: DifficultyHard}},
+	}
+	//right, right, wrong, wrong, right, right
+	session := newTestSession(ctx, strings.NewReader("a\na\nb\nb\na\na\n"), records)
+	session.Order = NewAdaptive(exact)
+
+	var asked []string
+	for record := range session.Start(ctx) {
+		asked = append(asked, record.Text)
+	}
+	want := "medium1,hard1,hard2,medium2,easy1,easy2"
+	if got := strings.Join(asked, ","); got != want {
+		t.Errorf("asked: want %s, got %s", want, got)
+	}
+	for idx := range records {
+		if records[idx].Text != asked[idx] {
+			t.Errorf("records: want the records in the order they were asked, got %q at %d", records[idx].Text, idx)
+		}
+	}
+}
+
+func TestAdaptive_skip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	exact, _ := ParseMatcher(matchExact)
+	records := []Record{
+		{Question: Question{Text: "easy1", Answer: "a", Difficulty: DifficultyEasy}},
+		{Question: Question{Text: "medium1", Answer: "a", Difficulty: DifficultyMedium}},
+		{Question: Question{Text: "medium2", Answer: "a", Difficulty: DifficultyMedium}},
+		{Question: Question{Text: "hard1", Answer: "a", Difficulty: DifficultyHard}},
+	}
+	//wrong, right, then a skip which must neither count the right answer again nor leave the question
+	input := strings.Join([]string{"b", "a", SkipCommand, "a", "a"}, "\n")
+	session := newTestSession(ctx, strings.NewReader(input+"\n"), records)
+	session.Order = NewAdaptive(exact)
+
+	var asked []string
+	for record := range session.Start(ctx) {
+		asked = append(asked, record.Text)
+	}
+	if want := "medium1,easy1,medium2,hard1"; strings.Join(asked, ",") != want {
+		t.Errorf("asked: want %s, got %s", want, strings.Join(asked, ","))
+	}
+	if skipped := session.Records[2]; skipped.Skips != 0 || skipped.Response != "a" {
+		t.Errorf("record %q: want the skip to be refused, got %d skips and response %q", skipped.Text, skipped.Skips, skipped.Response)
+	}
+	if out := session.Output.(*bytes.Buffer).String(); !strings.Contains(out, "Skipping questions is not possible") {
+		t.Errorf("output: want a message about skipping, got %q", out)
+	}
+}
+
+func TestEstimateSkill(t *testing.T) {
+	exact, _ := ParseMatcher(matchExact)
+	hard := Question{Answer: "a", Difficulty: DifficultyHard}
+	medium := Question{Answer: "a"}
+	tests := []struct {
+		records []Record
+		want    float64
+	}{
+		{nil, 0},
+		{[]Record{{Question: hard, Response: "a", Answered: true, Asked: true}}, 3},
+		{[]Record{{Question: medium, Response: "a", Answered: true, Asked: true}, {Question: medium, Asked: true}}, 2},
+		{[]Record{{Question: medium, Response: "b", Answered: true, Asked: true}, {Question: hard, Response: "a"}}, 1.5},
+	}
+	for _, tt := range tests {
+		if got := EstimateSkill(tt.records, exact); got != tt.want {
+			t.Errorf("EstimateSkill(%v): want %.1f, got %.1f", tt.records, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package quiz
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// uncategorized is the category of the questions which have none
+const uncategorized = "uncategorized"
+
+// CategoryName is the category of the question, or 'uncategorized' if it has none
+func (question *Question) CategoryName() string {
+	if question.Category == "" {
+		return uncategorized
+	}
+	return question.Category
+}
+
+// HasTag checks if the question is tagged with any of the tags, ignoring case
+func (question *Question) HasTag(tags ...string) bool {
+	for _, want := range tags {
+		for _, tag := range question.Tags {
+			if strings.EqualFold(tag, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Filter returns the questions which are in any of the categories and have any
+// of the tags, ignoring case. An empty list of categories or tags matches every question
+func (quiz *Quiz) Filter(categories, tags []string) *Quiz {
+	filtered := &Quiz{}
+	for _, question := range quiz.Questions {
+		if len(categories) > 0 && !containsFold(categories, question.CategoryName()) {
+			continue
+		}
+		if len(tags) > 0 && !question.HasTag(tags...) {
+			continue
+		}
+		filtered.Questions = append(filtered.Questions, question)
+	}
+	return filtered
+}
+
+// Sample picks up to count questions at random from each category. The picked
+// questions keep their order in the quiz
+func (quiz *Quiz) Sample(count int, rng *rand.Rand) *Quiz {
+	groups := make(map[string][]int)
+	for idx := range quiz.Questions {
+		category := quiz.Questions[idx].CategoryName()
+		groups[category] = append(groups[category], idx)
+	}
+
+	var picked []int
+	//the categories are visited in a fixed order for the sample to be reproducible
+	for _, category := range quiz.Categories() {
+		indexes := groups[category]
+		rng.Shuffle(len(indexes), func(i, j int) {
+			indexes[i], indexes[j] = indexes[j], indexes[i]
+		})
+		if len(indexes) > count {
+			indexes = indexes[:count]
+		}
+		picked = append(picked, indexes...)
+	}
+	sort.Ints(picked)
+
+	sampled := &Quiz{}
+	for _, idx := range picked {
+		sampled.Questions = append(sampled.Questions, quiz.Questions[idx])
+	}
+	return sampled
+}
+
+// Categories lists the categories of the quiz in the order they first appear
+func (quiz *Quiz) Categories() []string {
+	var (
+		categories []string
+		seen       = make(map[string]bool)
+	)
+	for idx := range quiz.Questions {
+		if category := quiz.Questions[idx].CategoryName(); !seen[category] {
+			seen[category] = true
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package quiz
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func newCategorizedQuiz() *Quiz {
+	return &Quiz{Questions: []Question{
+		{Text: "5+5", Answer: "10", Category: "Maths", Tags: []string{"easy"}},
+		{Text: "Capital of France?", Answer: "Paris", Category: "Geography", Tags: []string{"europe", "easy"}},
+		{Text: "7*8", Answer: "56", Category: "Maths", Tags: []string{"hard"}},
+		{Text: "Capital of Peru?", Answer: "Lima", Category: "Geography", Tags: []string{"americas"}},
+		{Text: "Who wrote Hamlet?", Answer: "Shakespeare"},
+	}}
+}
+
+func TestQuiz_Filter(t *testing.T) {
+	q := newCategorizedQuiz()
+	tests := []struct {
+		categories, tags []string
+		want             int
+	}{
+		{nil, nil, 5},
+		{[]string{"maths"}, nil, 2},
+		{[]string{"Maths", "uncategorized"}, nil, 3},
+		{nil, []string{"EASY"}, 2},
+		{[]string{"Geography"}, []string{"easy", "americas"}, 2},
+		{[]string{"History"}, nil, 0},
+	}
+	for _, tt := range tests {
+		if got := len(q.Filter(tt.categories, tt.tags).Questions); got != tt.want {
+			t.Errorf("Filter(%v, %v): want %d questions, got %d", tt.categories, tt.tags, tt.want, got)
+		}
+	}
+}
+
+func TestQuiz_Sample(t *testing.T) {
+	q := newCategorizedQuiz()
+	sampled := q.Sample(1, rand.New(rand.NewSource(1)))
+
+	counts := make(map[string]int)
+	for idx := range sampled.Questions {
+		counts[sampled.Questions[idx].CategoryName()]++
+	}
+	for _, category := range q.Categories() {
+		if counts[category] != 1 {
+			t.Errorf("Sample(1): want 1 question in %s, got %d", category, counts[category])
+		}
+	}
+}
+
+func TestNewReport_categories(t *testing.T) {
+	exact, _ := ParseMatcher(matchExact)
+	records := newCategorizedQuiz().Records()
+	for idx := range records {
+		records[idx].Response, records[idx].Answered = records[idx].Answer, idx != 2
+	}
+
+	report := NewReport(records, exact, 0)
+	if !report.IsCategorized() || len(report.Categories) != 3 {
+		t.Fatalf("Categories: want 3 categories, got %v", report.Categories)
+	}
+	if maths := report.Categories[0]; maths.Category != "Maths" || maths.Correct != 1 || maths.Total != 2 {
+		t.Errorf("Categories[0]: want Maths 1/2, got %s %d/%d", maths.Category, maths.Correct, maths.Total)
+	}
+	if other := report.Categories[2]; other.Category != uncategorized || other.Correct != 1 {
+		t.Errorf("Categories[2]: want %s 1/1, got %s %d/%d", uncategorized, other.Category, other.Correct, other.Total)
+	}
+}
@@ -0,0 +1,178 @@
+package quiz
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultOperators      = "+-"
+	defaultGeneratedCount = 10
+	defaultOperandMin     = 0
+	defaultOperandMax     = 10
+	// maxDigits keeps the operands and their products within an int64
+	maxDigits = 9
+)
+
+var operatorCategories = map[rune]string{
+	'+': "addition",
+	'-': "subtraction",
+	'*': "multiplication",
+	'/': "division",
+}
+
+// ArithmeticSource generates arithmetic questions with their answers, eg. '7*8'
+// with the answer '56'. Both operands of every question are within the range.
+// Subtractions never go below zero and divisions always have a whole answer,
+// which may be below the range. Each question is in the category of its operator
+type ArithmeticSource struct {
+	// Operators are any of '+', '-', '*' and '/'
+	Operators string
+	// Min and Max are the inclusive range of the operands
+	Min, Max int
+	Count    int
+	Rand     *rand.Rand
+}
+
+// ParseArithmetic creates an ArithmeticSource from a spec such as
+// 'ops=+-*/,min=1,max=12,count=20'. Instead of a range, 'digits=2' asks for operands
+// with the given number of digits, and 'x' may be used for multiplication. Any
+// setting which is left out has a default
+func ParseArithmetic(spec string) (*ArithmeticSource, error) {
+	src := &ArithmeticSource{
+		Operators: defaultOperators,
+		Min:       defaultOperandMin,
+		Max:       defaultOperandMax,
+		Count:     defaultGeneratedCount,
+	}
+	if strings.TrimSpace(spec) == "" {
+		return src, nil
+	}
+
+	for _, setting := range strings.Split(spec, ",") {
+		key, value, found := strings.Cut(setting, "=")
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		if !found || value == "" {
+			return nil, fmt.Errorf("invalid setting %q, expected key=value", setting)
+		}
+		if key == "ops" {
+			//x is easier to type than a * which the shell may expand
+			src.Operators = strings.ReplaceAll(strings.ToLower(value), "x", "*")
+			continue
+		}
+
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", key, value)
+		}
+		switch key {
+		case "min":
+			src.Min = number
+		case "max":
+			src.Max = number
+		case "count":
+			src.Count = number
+		case "digits":
+			if number < 1 || number > maxDigits {
+				return nil, fmt.Errorf("invalid digits %d, expected 1 to %d", number, maxDigits)
+			}
+			src.Max = int(math.Pow10(number)) - 1
+			if src.Min = 0; number > 1 {
+				src.Min = int(math.Pow10(number - 1))
+			}
+		default:
+			return nil, fmt.Errorf("unknown setting %q", key)
+		}
+	}
+	return src, src.validate()
+}
+
+func (src *ArithmeticSource) validate() error {
+	if src.Operators == "" {
+		return errors.New("no operators")
+	}
+	for _, op := range src.Operators {
+		if _, found := operatorCategories[op]; !found {
+			return fmt.Errorf("unsupported operator %q, expected any of +-*/", op)
+		}
+	}
+	switch {
+	case src.Min > src.Max:
+		return fmt.Errorf("min %d is greater than max %d", src.Min, src.Max)
+	case src.Min < 0 || src.Max >= int(math.Pow10(maxDigits)):
+		return fmt.Errorf("operands must be between 0 and %d", int(math.Pow10(maxDigits))-1)
+	case src.Count < 1:
+		return fmt.Errorf("invalid count %d", src.Count)
+	case src.Max == 0 && strings.ContainsRune(src.Operators, '/'):
+		return errors.New("division needs operands greater than 0")
+	}
+	return nil
+}
+
+// Questions generates Count questions, each with an operator picked at random
+func (src *ArithmeticSource) Questions() ([]Question, error) {
+	if err := src.validate(); err != nil {
+		return nil, err
+	}
+	rng := src.Rand
+	if rng == nil {
+		rng, _ = NewRand(0)
+	}
+
+	operators := []rune(src.Operators)
+	questions := make([]Question, 0, src.Count)
+	for i := 0; i < src.Count; i++ {
+		op := operators[rng.Intn(len(operators))]
+		a, b := src.operand(rng), src.operand(rng)
+
+		var result int
+		switch op {
+		case '+':
+			result = a + b
+		case '-':
+			if a < b {
+				a, b = b, a
+			}
+			result = a - b
+		case '*':
+			result = a * b
+		case '/':
+			a, b = src.division(rng)
+			result = a / b
+		}
+
+		questions = append(questions, Question{
+			Text:     fmt.Sprintf("%d%c%d", a, op, b),
+			Answer:   strconv.Itoa(result),
+			Category: operatorCategories[op],
+			Matcher:  &numericMatcher{},
+		})
+	}
+	return questions, nil
+}
+
+// division picks a dividend and a divisor within the range which divide without
+// a remainder. The divisor is at most half of Max when the range allows it, so
+// that the answer is not always 1
+func (src *ArithmeticSource) division(rng *rand.Rand) (int, int) {
+	low, high := src.Min, src.Max
+	if low < 1 {
+		low = 1
+	}
+	if half := src.Max / 2; half >= low {
+		high = half
+	}
+	divisor := low + rng.Intn(high-low+1)
+	//the answers whose dividend is within the range, which always include 1
+	minAnswer, maxAnswer := (src.Min+divisor-1)/divisor, src.Max/divisor
+	answer := minAnswer + rng.Intn(maxAnswer-minAnswer+1)
+	return answer * divisor, divisor
+}
+
+func (src *ArithmeticSource) operand(rng *rand.Rand) int {
+	return src.Min + rng.Intn(src.Max-src.Min+1)
+}
@@ -0,0 +1,127 @@
+package quiz
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseArithmetic(t *testing.T) {
+	tests := []struct {
+		spec     string
+		ops      string
+		min, max int
+		count    int
+		wantErr  bool
+	}{
+		{spec: "", ops: defaultOperators, min: defaultOperandMin, max: defaultOperandMax, count: defaultGeneratedCount},
+		{spec: "ops=+-*/, min=1, max=12, count=20", ops: "+-*/", min: 1, max: 12, count: 20},
+		{spec: "ops=x,digits=2", ops: "*", min: 10, max: 99, count: defaultGeneratedCount},
+		{spec: "digits=1", ops: defaultOperators, min: 0, max: 9, count: defaultGeneratedCount},
+		{spec: "ops=%", wantErr: true},
+		{spec: "min=5,max=2", wantErr: true},
+		{spec: "min=-1", wantErr: true},
+		{spec: "count=0", wantErr: true},
+		{spec: "digits=12", wantErr: true},
+		{spec: "ops=/,max=0", wantErr: true},
+		{spec: "max", wantErr: true},
+		{spec: "size=3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			src, err := ParseArithmetic(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseArithmetic(%q): want an error", tt.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseArithmetic(%q) received an error: %v", tt.spec, err)
+			}
+			if src.Operators != tt.ops || src.Min != tt.min || src.Max != tt.max || src.Count != tt.count {
+				t.Errorf("ParseArithmetic(%q) = %+v, want ops %q, range %d-%d and count %d",
+					tt.spec, *src, tt.ops, tt.min, tt.max, tt.count)
+			}
+		})
+	}
+}
+
+func TestArithmeticSource_Questions(t *testing.T) {
+	rng, _ := NewRand(7)
+	src := &ArithmeticSource{Operators: "+-*/", Min: 0, Max: 12, Count: 200, Rand: rng}
+	questions, err := src.Questions()
+	if err != nil {
+		t.Fatalf("Questions() received an error: %v", err)
+	}
+	if len(questions) != src.Count {
+		t.Fatalf("Questions(): want %d questions, got %d", src.Count, len(questions))
+	}
+
+	for _, question := range questions {
+		idx := strings.IndexAny(question.Text[1:], "+-*/") + 1
+		a, errA := strconv.Atoi(question.Text[:idx])
+		b, errB := strconv.Atoi(question.Text[idx+1:])
+		answer, errAnswer := strconv.Atoi(question.Answer)
+		if errA != nil || errB != nil || errAnswer != nil {
+			t.Fatalf("Questions(): invalid question %q with answer %q", question.Text, question.Answer)
+		}
+
+		var want int
+		switch question.Text[idx] {
+		case '+':
+			want = a + b
+		case '-':
+			want = a - b
+		case '*':
+			want = a * b
+		case '/':
+			if b == 0 || a%b != 0 {
+				t.Fatalf("Questions(): %q should divide without a remainder", question.Text)
+			}
+			want = a / b
+		}
+		if a < src.Min || a > src.Max || b < src.Min || b > src.Max {
+			t.Errorf("Questions(): %q has an operand outside %d-%d", question.Text, src.Min, src.Max)
+		}
+		if answer != want || answer < 0 {
+			t.Errorf("Questions(): %s = %s, want %d", question.Text, question.Answer, want)
+		}
+		if question.Category != operatorCategories[rune(question.Text[idx])] {
+			t.Errorf("Questions(): %q is in the category %q", question.Text, question.Category)
+		}
+		if !question.Matcher.Match(question.Answer, question.Answer+".0") {
+			t.Errorf("Questions(): %q should match answers numerically", question.Text)
+		}
+	}
+}
+
+func TestArithmeticSource_Questions_division(t *testing.T) {
+	for _, spec := range []string{"ops=/,digits=2,count=200", "ops=/,digits=1,count=200", "ops=/,min=5,max=7,count=50", "ops=/,min=0,max=1,count=20"} {
+		src, err := ParseArithmetic(spec)
+		if err != nil {
+			t.Fatalf("ParseArithmetic(%q) received an error: %v", spec, err)
+		}
+		src.Rand, _ = NewRand(3)
+		questions, err := src.Questions()
+		if err != nil {
+			t.Fatalf("Questions() received an error for %q: %v", spec, err)
+		}
+		answers := make(map[string]bool)
+		for _, question := range questions {
+			dividend, divisor, _ := strings.Cut(question.Text, "/")
+			a, _ := strconv.Atoi(dividend)
+			b, _ := strconv.Atoi(divisor)
+			if a < src.Min || a > src.Max || b < src.Min || b > src.Max || b == 0 {
+				t.Errorf("Questions(): %q has an operand outside %d-%d for %q", question.Text, src.Min, src.Max, spec)
+			} else if a%b != 0 || strconv.Itoa(a/b) != question.Answer {
+				t.Errorf("Questions(): %q should have the whole answer %s", question.Text, question.Answer)
+			}
+			answers[question.Answer] = true
+		}
+		if src.Max >= 2*src.Min && src.Max > 1 && len(answers) < 2 {
+			t.Errorf("Questions(): want more than one answer for %q, got %v", spec, answers)
+		}
+	}
+}
@@ -1,3 +1,18 @@
 module gophercises.com/quiz
 
 go 1.19
+
+require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/alecthomas/chroma/v2 v2.14.0
+	github.com/yuin/goldmark v1.7.8
+	go.etcd.io/bbolt v1.3.6
+	golang.org/x/term v0.14.0
+	golang.org/x/text v0.14.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/dlclark/regexp2 v1.11.0 // indirect
+	golang.org/x/sys v0.14.0 // indirect
+)
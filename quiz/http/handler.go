@@ -0,0 +1,339 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"gophercises.com/quiz"
+	"html/template"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sessionCookieName = "quiz_session"
+	sessionMaxAge     = 24 * time.Hour
+)
+
+// webSession is a quiz taken by a single browser. The deadlines are enforced
+// by the server, the countdown in the page is only a convenience
+type webSession struct {
+	sync.Mutex
+	records  []quiz.Record
+	seed     int64
+	current  int
+	started  time.Time
+	deadline time.Time
+	askedAt  time.Time
+	finished bool
+}
+
+// HandlerOption configures how the quiz is run in the browser
+type HandlerOption struct {
+	Tpl     *template.Template
+	Matcher quiz.Matcher
+	// Duration is the time limit of the whole quiz, if any
+	Duration time.Duration
+	// PerQuestion is the default time limit of each question, if any
+	PerQuestion time.Duration
+	Shuffle     bool
+	// Penalty is the share of its points a question loses when answered wrong
+	Penalty float64
+	// Seed replays the same order in every session. A new seed is picked for each session by default
+	Seed int64
+}
+
+type quizHandler struct {
+	HandlerOption
+	quiz     *quiz.Quiz
+	mux      *http.ServeMux
+	mu       sync.Mutex
+	sessions map[string]*webSession
+}
+
+type optionView struct {
+	Index  int    `json:"index"`
+	Letter string `json:"letter"`
+	Text   string `json:"text"`
+}
+
+type questionPage struct {
+	Number    int
+	Total     int
+	Question  template.HTML
+	Options   []optionView
+	Remaining int
+	TimedOut  bool
+}
+
+type resultsPage struct {
+	Report  *quiz.Report
+	Expired bool
+}
+
+// NewQuizHandler serves the quiz to every browser as a separate session. The
+// templates start.gohtml, question.gohtml and results.gohtml are required
+func NewQuizHandler(q *quiz.Quiz, opt HandlerOption) (http.Handler, error) {
+	if len(q.Questions) == 0 {
+		return nil, errors.New("the quiz has no questions")
+	}
+	//the tests of a code question run the code on the host, which must not be open to anyone who can reach the server
+	for idx := range q.Questions {
+		if q.Questions[idx].IsCode() {
+			return nil, errors.New("code questions can't be served on the web")
+		}
+	}
+	hnd := &quizHandler{
+		HandlerOption: opt.withDefaults(),
+		quiz:          q,
+		mux:           http.NewServeMux(),
+		sessions:      make(map[string]*webSession),
+	}
+	hnd.mux.HandleFunc("/", hnd.serveStart)
+	hnd.mux.HandleFunc("/question", hnd.serveQuestion)
+	hnd.mux.HandleFunc("/answer", hnd.serveAnswer)
+	hnd.mux.HandleFunc("/results", hnd.serveResults)
+	return hnd, nil
+}
+
+// withDefaults uses the default matcher if none was given
+func (opt HandlerOption) withDefaults() HandlerOption {
+	if opt.Matcher == nil {
+		opt.Matcher, _ = quiz.ParseMatcher(quiz.DefaultMatch)
+	}
+	return opt
+}
+
+func (hnd *quizHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	hnd.mux.ServeHTTP(w, r)
+}
+
+func (hnd *quizHandler) render(w http.ResponseWriter, name string, data any) {
+	if err := hnd.Tpl.ExecuteTemplate(w, name, data); err != nil {
+		log.Printf("error rendering template %s: %v", name, err)
+		http.Error(w, "Something went wrong...", http.StatusInternalServerError)
+	}
+}
+
+func (hnd *quizHandler) serveStart(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method == http.MethodPost {
+		hnd.startSession(w, r)
+		return
+	}
+	hnd.render(w, "start.gohtml", struct {
+		Total    int
+		Duration time.Duration
+	}{len(hnd.quiz.Questions), hnd.Duration})
+}
+
+func (hnd *quizHandler) startSession(w http.ResponseWriter, r *http.Request) {
+	id, err := newSessionID()
+	if err != nil {
+		http.Error(w, "Failed to start the quiz", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	rng, seed := quiz.NewRand(hnd.Seed)
+	session := &webSession{records: hnd.quiz.Records(), seed: seed, started: now}
+	if hnd.Duration > 0 {
+		session.deadline = now.Add(hnd.Duration)
+	}
+	if hnd.Shuffle {
+		quiz.Shuffle(session.records, rng)
+	}
+
+	hnd.mu.Lock()
+	for key, s := range hnd.sessions {
+		if now.Sub(s.started) > sessionMaxAge {
+			delete(hnd.sessions, key)
+		}
+	}
+	hnd.sessions[id] = session
+	hnd.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    id,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(sessionMaxAge.Seconds()),
+	})
+	http.Redirect(w, r, "/question", http.StatusSeeOther)
+}
+
+func (hnd *quizHandler) session(r *http.Request) *webSession {
+	cookie, err := r.Cookie(sessionCookieName)
+	if err != nil {
+		return nil
+	}
+	hnd.mu.Lock()
+	defer hnd.mu.Unlock()
+	return hnd.sessions[cookie.Value]
+}
+
+func (hnd *quizHandler) serveQuestion(w http.ResponseWriter, r *http.Request) {
+	session := hnd.session(r)
+	if session == nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	session.Lock()
+	defer session.Unlock()
+	now := time.Now()
+	session.expire(now, hnd.PerQuestion)
+	if session.finished {
+		http.Redirect(w, r, "/results", http.StatusSeeOther)
+		return
+	}
+
+	record := &session.records[session.current]
+	if !record.Asked {
+		record.Asked = true
+		session.askedAt = now
+	}
+
+	page := questionPage{
+		Number:    session.current + 1,
+		Total:     len(session.records),
+		Question:  template.HTML(record.HTML()),
+		Remaining: int(math.Ceil(session.remaining(now, record.TimeLimitOr(hnd.PerQuestion)).Seconds())),
+		TimedOut:  r.URL.Query().Has("timeout"),
+	}
+	for idx, option := range record.Options {
+		page.Options = append(page.Options, optionView{Index: idx, Letter: quiz.OptionLetter(idx), Text: option})
+	}
+	hnd.render(w, "question.gohtml", &page)
+}
+
+func (hnd *quizHandler) serveAnswer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	session := hnd.session(r)
+	if session == nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	session.Lock()
+	defer session.Unlock()
+	now := time.Now()
+	current := session.current
+	session.expire(now, hnd.PerQuestion)
+
+	number, _ := strconv.Atoi(r.FormValue("number"))
+	switch {
+	case session.finished:
+		http.Redirect(w, r, "/results", http.StatusSeeOther)
+	case session.current != current:
+		http.Redirect(w, r, "/question?timeout", http.StatusSeeOther)
+	case number != session.current+1:
+		//a stale page was submitted, eg. after using the back button
+		http.Redirect(w, r, "/question", http.StatusSeeOther)
+	default:
+		record := &session.records[session.current]
+		record.Response, record.Answered = strings.TrimSpace(formResponse(r)), true
+		record.Elapsed = now.Sub(session.askedAt)
+		session.next()
+		http.Redirect(w, r, "/question", http.StatusSeeOther)
+	}
+}
+
+// formResponse is the response submitted with a form. An option is sent as its
+// index, and recorded as its letter, which always picks the option it was shown with
+func formResponse(r *http.Request) string {
+	option := r.FormValue("option")
+	if option == "" {
+		return r.FormValue("response")
+	}
+	idx, err := strconv.Atoi(option)
+	if err != nil || idx < 0 || idx > 'Z'-'A' {
+		return ""
+	}
+	return quiz.OptionLetter(idx)
+}
+
+func (hnd *quizHandler) serveResults(w http.ResponseWriter, r *http.Request) {
+	session := hnd.session(r)
+	if session == nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	session.Lock()
+	defer session.Unlock()
+	now := time.Now()
+	session.expire(now, hnd.PerQuestion)
+	if !session.finished {
+		http.Redirect(w, r, "/question", http.StatusSeeOther)
+		return
+	}
+	expired := !session.deadline.IsZero() && !now.Before(session.deadline)
+	report := quiz.NewReport(session.records, hnd.Matcher, hnd.Penalty)
+	report.Seed = session.seed
+	hnd.render(w, "results.gohtml", &resultsPage{Report: report, Expired: expired})
+}
+
+// expire ends the session once its deadline has passed and moves past the
+// current question if it ran out of time
+func (session *webSession) expire(now time.Time, perQuestion time.Duration) {
+	if session.finished {
+		return
+	}
+	if !session.deadline.IsZero() && !now.Before(session.deadline) {
+		if record := &session.records[session.current]; record.Asked {
+			record.Elapsed = session.deadline.Sub(session.askedAt)
+		}
+		session.finished = true
+		return
+	}
+
+	record := &session.records[session.current]
+	if limit := record.TimeLimitOr(perQuestion); record.Asked && limit > 0 && now.Sub(session.askedAt) >= limit {
+		record.Elapsed = limit
+		session.next()
+	}
+}
+
+func (session *webSession) next() {
+	session.current++
+	if session.current >= len(session.records) {
+		session.finished = true
+	}
+}
+
+// remaining is the time left to answer the current question, which is zero when there is no limit
+func (session *webSession) remaining(now time.Time, questionLimit time.Duration) time.Duration {
+	var remaining time.Duration
+	if !session.deadline.IsZero() {
+		remaining = session.deadline.Sub(now)
+	}
+	if questionLimit > 0 {
+		left := session.askedAt.Add(questionLimit).Sub(now)
+		if remaining == 0 || left < remaining {
+			remaining = left
+		}
+	}
+	return remaining
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,147 @@
+package http
+
+import (
+	"gophercises.com/quiz"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// quizClient takes a quiz served by a test server, keeping the session cookie
+// and following the redirects like a browser does
+type quizClient struct {
+	t      *testing.T
+	server *httptest.Server
+	client *http.Client
+}
+
+func newQuizClient(t *testing.T, q *quiz.Quiz, opt HandlerOption) *quizClient {
+	t.Helper()
+	opt.Tpl = template.Must(template.ParseGlob("../web/templates/*.gohtml"))
+	handler, err := NewQuizHandler(q, opt)
+	if err != nil {
+		t.Fatalf("NewQuizHandler() received an error: %v", err)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	jar, _ := cookiejar.New(nil)
+	return &quizClient{t: t, server: server, client: &http.Client{Jar: jar}}
+}
+
+// do sends the request and returns the path of the page it ended on, along with its body
+func (c *quizClient) do(method, path string, form url.Values) (string, string) {
+	c.t.Helper()
+	req, err := http.NewRequest(method, c.server.URL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		c.t.Fatalf("NewRequest() received an error: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.t.Fatalf("%s %s received an error: %v", method, path, err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		c.t.Fatalf("%s %s: want status 200, got %d: %s", method, path, resp.StatusCode, body)
+	}
+	return resp.Request.URL.RequestURI(), string(body)
+}
+
+func (c *quizClient) answer(number int, form url.Values) (string, string) {
+	c.t.Helper()
+	form.Set("number", strconv.Itoa(number))
+	return c.do(http.MethodPost, "/answer", form)
+}
+
+func newTestQuiz() *quiz.Quiz {
+	return &quiz.Quiz{Questions: []quiz.Question{
+		{Text: "5+5", Answer: "10"},
+		{Text: "Pick the odd one", Options: []string{"4", "7", "8"}, Answer: "7"},
+	}}
+}
+
+// want checks that the request ended on the page, showing the text
+func want(t *testing.T, page, body, wantPage, wantText string) {
+	t.Helper()
+	if page != wantPage || !strings.Contains(body, wantText) {
+		t.Errorf("want %q showing %q, got %q:\n%s", wantPage, wantText, page, body)
+	}
+}
+
+func TestNewQuizHandler_errors(t *testing.T) {
+	if _, err := NewQuizHandler(&quiz.Quiz{}, HandlerOption{}); err == nil {
+		t.Errorf("NewQuizHandler(): expected an error for a quiz without questions")
+	}
+	code := &quiz.Quiz{Questions: []quiz.Question{{Text: "Add", Code: &quiz.CodeTests{}}}}
+	if _, err := NewQuizHandler(code, HandlerOption{}); err == nil {
+		t.Errorf("NewQuizHandler(): expected an error for a code question")
+	}
+}
+
+func TestQuizHandler(t *testing.T) {
+	c := newQuizClient(t, newTestQuiz(), HandlerOption{})
+	page, body := c.do(http.MethodGet, "/question", nil)
+	want(t, page, body, "/", "<form")
+
+	page, body = c.do(http.MethodPost, "/", nil)
+	want(t, page, body, "/question", "Question 1/2")
+	page, body = c.do(http.MethodGet, "/results", nil)
+	want(t, page, body, "/question", "5+5")
+
+	page, body = c.answer(1, url.Values{"response": {" 10 "}})
+	want(t, page, body, "/question", "Question 2/2")
+	if !strings.Contains(body, `value="1"`) || !strings.Contains(body, "B) 7") {
+		t.Errorf("want the options with their index and letter, got:\n%s", body)
+	}
+
+	//a stale page, eg. after going back, is not recorded for the current question
+	page, body = c.answer(1, url.Values{"response": {"7"}})
+	want(t, page, body, "/question", "Question 2/2")
+
+	page, body = c.answer(2, url.Values{"option": {"1"}})
+	want(t, page, body, "/results", "You scored 2/2")
+	if strings.Contains(body, "Timeout!") {
+		t.Errorf("want the results without a timeout, got:\n%s", body)
+	}
+	page, _ = c.do(http.MethodGet, "/question", nil)
+	if page != "/results" {
+		t.Errorf("want a finished quiz to show the results, got %q", page)
+	}
+}
+
+func TestQuizHandler_questionLimit(t *testing.T) {
+	c := newQuizClient(t, newTestQuiz(), HandlerOption{PerQuestion: 50 * time.Millisecond})
+	c.do(http.MethodPost, "/", nil)
+	time.Sleep(60 * time.Millisecond)
+
+	page, body := c.answer(1, url.Values{"response": {"10"}})
+	want(t, page, body, "/question?timeout", "Time's up for that question!")
+	if !strings.Contains(body, "Question 2/2") {
+		t.Errorf("want the next question once the time is up, got:\n%s", body)
+	}
+	page, body = c.answer(2, url.Values{"option": {"1"}})
+	want(t, page, body, "/results", "You scored 1/2")
+}
+
+func TestQuizHandler_deadline(t *testing.T) {
+	c := newQuizClient(t, newTestQuiz(), HandlerOption{Duration: 200 * time.Millisecond})
+	c.do(http.MethodPost, "/", nil)
+	page, body := c.answer(1, url.Values{"response": {"10"}})
+	want(t, page, body, "/question", "Question 2/2")
+	time.Sleep(250 * time.Millisecond)
+
+	page, body = c.answer(2, url.Values{"option": {"1"}})
+	want(t, page, body, "/results", "Timeout!")
+	if !strings.Contains(body, "You scored 1/2") {
+		t.Errorf("want the answer after the deadline not to count, got:\n%s", body)
+	}
+}
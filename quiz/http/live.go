@@ -0,0 +1,515 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gophercises.com/quiz"
+	"log"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLiveQuestionLimit = 20 * time.Second
+	liveRevealPause          = 3 * time.Second
+	playerCookieName         = "quiz_player"
+	joinCodeAlphabet         = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	joinCodeLength           = 6
+
+	eventLobby    = "lobby"
+	eventQuestion = "question"
+	eventReveal   = "reveal"
+	eventFinished = "finished"
+)
+
+// liveEvent is a server-sent event broadcast to every player and the host
+type liveEvent struct {
+	name string
+	data []byte
+}
+
+// liveBroker fans out events to the subscribed event streams. New subscribers
+// receive the last event straight away so that they can catch up with the game
+type liveBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan liveEvent]struct{}
+	last        *liveEvent
+}
+
+type livePlayer struct {
+	id         string
+	name       string
+	correct    int
+	answerTime time.Duration
+	answered   map[int]bool
+}
+
+type LeaderboardEntry struct {
+	Rank    int    `json:"rank"`
+	Name    string `json:"name"`
+	Correct int    `json:"correct"`
+	Time    string `json:"time"`
+}
+
+type liveQuestion struct {
+	Number   int    `json:"number"`
+	Total    int    `json:"total"`
+	Question string `json:"question"`
+	// HTML is the question rendered for the page, see quiz.Question.HTML
+	HTML      string       `json:"html"`
+	Options   []optionView `json:"options"`
+	Remaining int          `json:"remaining"`
+}
+
+type liveReveal struct {
+	Number      int                `json:"number"`
+	Answer      string             `json:"answer"`
+	Leaderboard []LeaderboardEntry `json:"leaderboard"`
+}
+
+type liveLobby struct {
+	Players []string `json:"players"`
+}
+
+type liveFinished struct {
+	Timeout     bool               `json:"timeout"`
+	Leaderboard []LeaderboardEntry `json:"leaderboard"`
+}
+
+// LiveGame is a quiz hosted for many players at once. Every player is asked the
+// same question at the same moment, and the game moves on once all players have
+// answered or the question runs out of time
+type LiveGame struct {
+	HandlerOption
+	records     []quiz.Record
+	code        string
+	hostToken   string
+	seed        int64
+	broker      *liveBroker
+	mux         *http.ServeMux
+	startOnce   sync.Once
+	start       chan struct{}
+	allAnswered chan struct{}
+
+	mu       sync.Mutex
+	players  map[string]*livePlayer
+	current  int
+	askedAt  time.Time
+	limit    time.Duration
+	finished bool
+}
+
+func newLiveBroker() *liveBroker {
+	return &liveBroker{subscribers: make(map[chan liveEvent]struct{})}
+}
+
+func (broker *liveBroker) subscribe() chan liveEvent {
+	ch := make(chan liveEvent, 8)
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	broker.subscribers[ch] = struct{}{}
+	if broker.last != nil {
+		ch <- *broker.last
+	}
+	return ch
+}
+
+func (broker *liveBroker) unsubscribe(ch chan liveEvent) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	delete(broker.subscribers, ch)
+}
+
+func (broker *liveBroker) publish(name string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("failed to encode %s event: %v", name, err)
+		return
+	}
+	event := liveEvent{name: name, data: data}
+
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	broker.last = &event
+	for ch := range broker.subscribers {
+		select {
+		case ch <- event:
+		default:
+			//a subscriber which can't keep up misses the event rather than stalling the game
+		}
+	}
+}
+
+// NewLiveGame creates a game which asks the questions of the quiz in order. The
+// templates join.gohtml, play.gohtml and host.gohtml are required
+func NewLiveGame(q *quiz.Quiz, opt HandlerOption) (*LiveGame, error) {
+	//a live question is only open for a moment, which is too short to write and test code
+	for idx := range q.Questions {
+		if q.Questions[idx].IsCode() {
+			return nil, errors.New("code questions can't be played live")
+		}
+	}
+	code, err := randomCode(joinCodeLength)
+	if err != nil {
+		return nil, err
+	}
+	hostToken, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	game := &LiveGame{
+		HandlerOption: opt.withDefaults(),
+		records:       q.Records(),
+		code:          code,
+		hostToken:     hostToken,
+		broker:        newLiveBroker(),
+		mux:           http.NewServeMux(),
+		start:         make(chan struct{}),
+		allAnswered:   make(chan struct{}, 1),
+		players:       make(map[string]*livePlayer),
+	}
+	if game.Shuffle {
+		rng, seed := quiz.NewRand(opt.Seed)
+		quiz.Shuffle(game.records, rng)
+		game.seed = seed
+	}
+	game.mux.HandleFunc("/", game.serveJoin)
+	game.mux.HandleFunc("/play", game.servePlay)
+	game.mux.HandleFunc("/host", game.serveHost)
+	game.mux.HandleFunc("/events", game.serveEvents)
+	game.mux.HandleFunc("/answer", game.serveAnswer)
+	game.broker.publish(eventLobby, &liveLobby{Players: []string{}})
+	return game, nil
+}
+
+func (game *LiveGame) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	game.mux.ServeHTTP(w, r)
+}
+
+// Code is the code players need to join the game
+func (game *LiveGame) Code() string {
+	return game.code
+}
+
+// HostToken grants access to the host page, from which the game can be followed and started
+func (game *LiveGame) HostToken() string {
+	return game.hostToken
+}
+
+// Seed replays the order of the questions of a shuffled game, 0 if it was not shuffled
+func (game *LiveGame) Seed() int64 {
+	return game.seed
+}
+
+// Begin lets the game loop start asking questions. Only the first call has an effect
+func (game *LiveGame) Begin() {
+	game.startOnce.Do(func() {
+		close(game.start)
+	})
+}
+
+// Run waits for the host to start the game and then asks each question in turn,
+// with the same time limits as a quiz taken in the terminal
+func (game *LiveGame) Run(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		return
+	case <-game.start:
+	}
+
+	var cancel context.CancelFunc
+	if game.Duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, game.Duration)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	for idx := range game.records {
+		record := &game.records[idx]
+		limit := record.TimeLimitOr(game.PerQuestion)
+		if limit <= 0 {
+			limit = defaultLiveQuestionLimit
+		}
+		game.ask(idx, limit)
+
+		timer := time.NewTimer(limit)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			game.finish(true)
+			return
+		case <-timer.C:
+		case <-game.allAnswered:
+			timer.Stop()
+		}
+
+		game.mu.Lock()
+		game.current = 0
+		game.broker.publish(eventReveal, &liveReveal{Number: idx + 1, Answer: record.Answer, Leaderboard: game.leaderboard()})
+		game.mu.Unlock()
+
+		if idx < len(game.records)-1 {
+			select {
+			case <-ctx.Done():
+				game.finish(true)
+				return
+			case <-time.After(liveRevealPause):
+			}
+		}
+	}
+	game.finish(false)
+}
+
+func (game *LiveGame) ask(idx int, limit time.Duration) {
+	record := &game.records[idx]
+	question := liveQuestion{
+		Number:    idx + 1,
+		Total:     len(game.records),
+		Question:  record.Text,
+		HTML:      record.HTML(),
+		Options:   []optionView{},
+		Remaining: int(limit.Seconds()),
+	}
+	for optIdx, option := range record.Options {
+		question.Options = append(question.Options, optionView{Index: optIdx, Letter: quiz.OptionLetter(optIdx), Text: option})
+	}
+
+	game.mu.Lock()
+	defer game.mu.Unlock()
+	select {
+	case <-game.allAnswered:
+	default:
+	}
+	game.current, game.askedAt, game.limit = idx+1, time.Now(), limit
+	game.broker.publish(eventQuestion, &question)
+}
+
+func (game *LiveGame) finish(timeout bool) {
+	game.mu.Lock()
+	defer game.mu.Unlock()
+	game.current, game.finished = 0, true
+	game.broker.publish(eventFinished, &liveFinished{Timeout: timeout, Leaderboard: game.leaderboard()})
+}
+
+// answer scores the response of a player to the current question. Only the
+// first response to a question counts, and only while the question is open
+func (game *LiveGame) answer(player *livePlayer, number int, response string) error {
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	now := time.Now()
+	switch {
+	case game.current == 0 || number != game.current:
+		return fmt.Errorf("question #%d is not open", number)
+	case now.Sub(game.askedAt) > game.limit:
+		return fmt.Errorf("time's up for question #%d", number)
+	case player.answered[number]:
+		return fmt.Errorf("question #%d was already answered", number)
+	}
+
+	record := game.records[number-1]
+	record.Response, record.Answered = strings.TrimSpace(response), true
+	player.answered[number] = true
+	if record.IsCorrect(game.Matcher) {
+		player.correct++
+		player.answerTime += now.Sub(game.askedAt)
+	}
+
+	for _, p := range game.players {
+		if !p.answered[number] {
+			return nil
+		}
+	}
+	select {
+	case game.allAnswered <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// leaderboard ranks the players by the number of correct answers, and then by
+// the time they took to give them. It expects game.mu to be held
+func (game *LiveGame) leaderboard() []LeaderboardEntry {
+	players := make([]*livePlayer, 0, len(game.players))
+	for _, player := range game.players {
+		players = append(players, player)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		if players[i].correct != players[j].correct {
+			return players[i].correct > players[j].correct
+		}
+		if players[i].answerTime != players[j].answerTime {
+			return players[i].answerTime < players[j].answerTime
+		}
+		return players[i].name < players[j].name
+	})
+
+	entries := make([]LeaderboardEntry, 0, len(players))
+	for idx, player := range players {
+		entries = append(entries, LeaderboardEntry{
+			Rank:    idx + 1,
+			Name:    player.name,
+			Correct: player.correct,
+			Time:    player.answerTime.Round(100 * time.Millisecond).String(),
+		})
+	}
+	return entries
+}
+
+func (game *LiveGame) player(r *http.Request) *livePlayer {
+	cookie, err := r.Cookie(playerCookieName)
+	if err != nil {
+		return nil
+	}
+	game.mu.Lock()
+	defer game.mu.Unlock()
+	return game.players[cookie.Value]
+}
+
+func (game *LiveGame) render(w http.ResponseWriter, name string, data any) {
+	if err := game.Tpl.ExecuteTemplate(w, name, data); err != nil {
+		log.Printf("error rendering template %s: %v", name, err)
+		http.Error(w, "Something went wrong...", http.StatusInternalServerError)
+	}
+}
+
+func (game *LiveGame) serveJoin(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		game.render(w, "join.gohtml", struct{ Error string }{})
+		return
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(r.FormValue("code")))
+	name := strings.TrimSpace(r.FormValue("name"))
+	if code != game.code {
+		game.render(w, "join.gohtml", struct{ Error string }{"That is not the code of this game"})
+		return
+	}
+	if name == "" {
+		game.render(w, "join.gohtml", struct{ Error string }{"Please pick a name"})
+		return
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		http.Error(w, "Failed to join the game", http.StatusInternalServerError)
+		return
+	}
+
+	game.mu.Lock()
+	for _, player := range game.players {
+		if strings.EqualFold(player.name, name) {
+			game.mu.Unlock()
+			game.render(w, "join.gohtml", struct{ Error string }{"That name is already taken"})
+			return
+		}
+	}
+	game.players[id] = &livePlayer{id: id, name: name, answered: make(map[int]bool)}
+	var names []string
+	for _, player := range game.players {
+		names = append(names, player.name)
+	}
+	sort.Strings(names)
+	if game.current == 0 && !game.finished {
+		game.broker.publish(eventLobby, &liveLobby{Players: names})
+	}
+	game.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{Name: playerCookieName, Value: id, Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode})
+	http.Redirect(w, r, "/play", http.StatusSeeOther)
+}
+
+func (game *LiveGame) servePlay(w http.ResponseWriter, r *http.Request) {
+	player := game.player(r)
+	if player == nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	game.render(w, "play.gohtml", struct{ Name string }{player.name})
+}
+
+func (game *LiveGame) serveHost(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("token") != game.hostToken {
+		http.Error(w, "Only the host can do that", http.StatusForbidden)
+		return
+	}
+	if r.Method == http.MethodPost {
+		game.Begin()
+		http.Redirect(w, r, "/host?token="+game.hostToken, http.StatusSeeOther)
+		return
+	}
+	game.render(w, "host.gohtml", struct{ Code, Token string }{game.code, game.hostToken})
+}
+
+func (game *LiveGame) serveAnswer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	player := game.player(r)
+	if player == nil {
+		http.Error(w, "Join the game first", http.StatusUnauthorized)
+		return
+	}
+	number, _ := strconv.Atoi(r.FormValue("number"))
+	if err := game.answer(player, number, formResponse(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (game *LiveGame) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	if game.player(r) == nil && r.FormValue("token") != game.hostToken {
+		http.Error(w, "Join the game first", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	events := game.broker.subscribe()
+	defer game.broker.unsubscribe(events)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-events:
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.name, event.data)
+			flusher.Flush()
+		}
+	}
+}
+
+func randomCode(length int) (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(joinCodeAlphabet)))
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(joinCodeAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
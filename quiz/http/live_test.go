@@ -0,0 +1,147 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"gophercises.com/quiz"
+	"testing"
+	"time"
+)
+
+// newTestGame creates a game of simple questions, joined by the named players
+func newTestGame(t *testing.T, names ...string) *LiveGame {
+	t.Helper()
+	q := &quiz.Quiz{Questions: []quiz.Question{{Text: "1+1", Answer: "2"}, {Text: "2+2", Answer: "4"}}}
+	game, err := NewLiveGame(q, HandlerOption{})
+	if err != nil {
+		t.Fatalf("NewLiveGame() received an error: %v", err)
+	}
+	for _, name := range names {
+		game.players[name] = &livePlayer{id: name, name: name, answered: make(map[int]bool)}
+	}
+	return game
+}
+
+func TestLiveGame_answer(t *testing.T) {
+	game := newTestGame(t, "ann", "bob")
+	ann, bob := game.players["ann"], game.players["bob"]
+	if err := game.answer(ann, 1, "2"); err == nil {
+		t.Errorf("answer(): expected an error before the question is open")
+	}
+
+	game.ask(0, time.Minute)
+	if err := game.answer(ann, 2, "4"); err == nil {
+		t.Errorf("answer(): expected an error for a question which is not open")
+	}
+	if err := game.answer(ann, 1, " 2 "); err != nil {
+		t.Fatalf("answer() received an error: %v", err)
+	}
+	if ann.correct != 1 || !ann.answered[1] {
+		t.Errorf("answer(): want the answer to be recorded as correct, got %d correct, answered %t", ann.correct, ann.answered[1])
+	}
+	if err := game.answer(ann, 1, "3"); err == nil {
+		t.Errorf("answer(): expected an error for a question which was already answered")
+	}
+	if ann.correct != 1 {
+		t.Errorf("answer(): want only the first response to count, got %d correct", ann.correct)
+	}
+
+	select {
+	case <-game.allAnswered:
+		t.Fatalf("answer(): want the question to stay open until everyone has answered")
+	default:
+	}
+	if err := game.answer(bob, 1, "3"); err != nil {
+		t.Fatalf("answer() received an error: %v", err)
+	}
+	if bob.correct != 0 || bob.answerTime != 0 {
+		t.Errorf("answer(): want a wrong answer not to be timed, got %d in %v", bob.correct, bob.answerTime)
+	}
+	select {
+	case <-game.allAnswered:
+	default:
+		t.Errorf("answer(): want the game to move on once everyone has answered")
+	}
+}
+
+func TestLiveGame_answer_late(t *testing.T) {
+	game := newTestGame(t, "ann")
+	ann := game.players["ann"]
+	game.ask(0, time.Second)
+	game.askedAt = time.Now().Add(-2 * time.Second)
+	if err := game.answer(ann, 1, "2"); err == nil {
+		t.Errorf("answer(): expected an error once the time is up")
+	}
+	if ann.answered[1] || ann.correct != 0 {
+		t.Errorf("answer(): want a late answer not to count, got %+v", ann)
+	}
+}
+
+func TestLiveGame_leaderboard(t *testing.T) {
+	game := newTestGame(t)
+	for _, player := range []*livePlayer{
+		{name: "slow", correct: 2, answerTime: 9 * time.Second},
+		{name: "fast", correct: 2, answerTime: 3 * time.Second},
+		{name: "wrong", correct: 0},
+		{name: "best", correct: 3, answerTime: 20 * time.Second},
+		{name: "also fast", correct: 2, answerTime: 3 * time.Second},
+	} {
+		game.players[player.name] = player
+	}
+
+	entries := game.leaderboard()
+	want := []string{"best", "also fast", "fast", "slow", "wrong"}
+	if len(entries) != len(want) {
+		t.Fatalf("leaderboard(): want %d entries, got %d", len(want), len(entries))
+	}
+	for idx, entry := range entries {
+		if entry.Name != want[idx] || entry.Rank != idx+1 {
+			t.Errorf("leaderboard(): want %s ranked %d, got %s ranked %d", want[idx], idx+1, entry.Name, entry.Rank)
+		}
+	}
+	if entries[1].Time != "3s" {
+		t.Errorf("leaderboard(): want the time of the correct answers, got %s", entries[1].Time)
+	}
+}
+
+func TestLiveGame_Run(t *testing.T) {
+	game := newTestGame(t, "ann")
+	game.records = game.records[:1]
+	ann := game.players["ann"]
+	events := game.broker.subscribe()
+	defer game.broker.unsubscribe(events)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		game.Run(ctx)
+		close(done)
+	}()
+	game.Begin()
+
+	//the question is open for the default limit, so the game only ends early once everyone has answered
+	for event := range events {
+		if event.name == eventQuestion {
+			break
+		}
+	}
+	if err := game.answer(ann, 1, "2"); err != nil {
+		t.Fatalf("answer() received an error: %v", err)
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatalf("Run(): want the game to move on once everyone has answered")
+	}
+
+	game.mu.Lock()
+	defer game.mu.Unlock()
+	if !game.finished {
+		t.Errorf("Run(): want the game to be finished")
+	}
+	var finished liveFinished
+	if err := json.Unmarshal(game.broker.last.data, &finished); err != nil || finished.Timeout || len(finished.Leaderboard) != 1 {
+		t.Errorf("Run(): want the final leaderboard without a timeout, got %s", game.broker.last.data)
+	}
+}
@@ -0,0 +1,425 @@
+package quiz
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The flashcard tools whose exports can be imported
+const (
+	ImportAnki    = "anki"
+	ImportQuizlet = "quizlet"
+
+	defaultImportSeparator = "\t"
+	defaultCardSeparator   = "\n"
+	ankiHeaderPrefix       = "#"
+	clozeBlank             = "[...]"
+)
+
+var (
+	htmlBreak = regexp.MustCompile(`(?i)<br\s*/?>|</(div|p|li|h[1-6])>`)
+	htmlTag   = regexp.MustCompile(`<[^>]*>`)
+	// ankiMedia matches the references to sounds, which can't be played in a quiz
+	ankiMedia = regexp.MustCompile(`\[sound:[^\]]*]`)
+	// ankiCloze matches a cloze deletion such as {{c1::Paris}} or {{c1::Paris::city}}
+	ankiCloze = regexp.MustCompile(`\{\{c\d+::(.*?)(?:::(.*?))?}}`)
+)
+
+// Importer converts the cards exported by another flashcard tool into a question
+// bank. The front of each card becomes the question and its back the answer
+type Importer struct {
+	// From is the tool which exported the cards, anki or quizlet
+	From string
+	// Separator separates the fields of a card, a tab by default. An Anki export
+	// which names its separator in its header uses that one instead. The names tab,
+	// comma, semicolon, space, pipe and colon are accepted
+	Separator string
+	// CardSeparator separates the cards of a Quizlet export, a new line by default.
+	// An Anki export has a card per line, unless a field is quoted
+	CardSeparator string
+	// Category is the category of every question, unless an Anki export has the deck of each card
+	Category string
+	// Tags are added to the tags of every question
+	Tags []string
+	// Reverse asks for the front of each card given its back
+	Reverse bool
+}
+
+// Import reads the cards and writes them as a question bank of the given format.
+// It returns the number of questions written, and writes nothing if any card is
+// not a valid question
+func (im *Importer) Import(r io.Reader, w io.Writer, format string) (int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the cards: %v", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	var cards []sourceEntry
+	switch im.From {
+	case ImportAnki:
+		cards, err = im.ankiCards(data)
+	case ImportQuizlet:
+		cards, err = im.quizletCards(data)
+	default:
+		err = fmt.Errorf("unsupported export format %q", im.From)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	entries := make([]questionEntry, 0, len(cards))
+	for _, card := range cards {
+		entry := card.questionEntry
+		if card.err == nil {
+			entry = im.adjust(entry)
+			_, card.err = entry.toQuestion()
+		}
+		if card.err != nil {
+			return 0, &SourceError{Line: card.line, Column: card.column, Err: card.err}
+		}
+		entries = append(entries, entry)
+	}
+	if err := writeBank(w, format, entries); err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// adjust applies the settings which are shared by every card
+func (im *Importer) adjust(entry questionEntry) questionEntry {
+	entry.Question, entry.Answer = strings.TrimSpace(entry.Question), strings.TrimSpace(entry.Answer)
+	for idx := range entry.Parts {
+		entry.Parts[idx] = strings.TrimSpace(entry.Parts[idx])
+	}
+	if im.Reverse && len(entry.Parts) == 0 {
+		entry.Question, entry.Answer = entry.Answer, entry.Question
+	}
+	if entry.Category == "" {
+		entry.Category = im.Category
+	}
+	for _, tag := range im.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" && !containsString(entry.Tags, tag) {
+			entry.Tags = append(entry.Tags, tag)
+		}
+	}
+	return entry
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// separatorByName converts the name of a separator, as used in the header of
+// Anki exports, to the separator itself. Any other name is the separator as it is
+func separatorByName(name, fallback string) string {
+	switch strings.ToLower(name) {
+	case "":
+		return fallback
+	case "tab", `\t`:
+		return "\t"
+	case "comma":
+		return ","
+	case "semicolon":
+		return ";"
+	case "space":
+		return " "
+	case "pipe":
+		return "|"
+	case "colon":
+		return ":"
+	case "newline", `\n`:
+		return "\n"
+	}
+	return name
+}
+
+// quizletCards reads a Quizlet export, in which each card is a term and its
+// definition. The separators are chosen when exporting, and quotes have no meaning
+func (im *Importer) quizletCards(data []byte) ([]sourceEntry, error) {
+	separator := separatorByName(im.Separator, defaultImportSeparator)
+	cardSeparator := separatorByName(im.CardSeparator, defaultCardSeparator)
+	if separator == cardSeparator {
+		return nil, errors.New("the cards and their fields need different separators")
+	}
+
+	var cards []sourceEntry
+	text := string(data)
+	for offset := 0; offset < len(text); {
+		card := text[offset:]
+		next := strings.Index(card, cardSeparator)
+		if next >= 0 {
+			card = card[:next]
+		}
+		start := offset
+		if next < 0 {
+			offset = len(text)
+		} else {
+			offset += next + len(cardSeparator)
+		}
+		if strings.TrimSpace(card) == "" {
+			continue
+		}
+
+		line, column := lineColumn(data, int64(start+len(card)-len(strings.TrimLeft(card, " \t\r\n"))))
+		entry := sourceEntry{line: line, column: column}
+		term, definition, found := strings.Cut(strings.TrimSuffix(card, "\r"), separator)
+		if found {
+			entry.Question, entry.Answer = term, definition
+		} else {
+			entry.err = fmt.Errorf("missing the separator %q between the term and the definition", separator)
+		}
+		cards = append(cards, entry)
+	}
+	return cards, nil
+}
+
+// ankiHeader is the header of an Anki export, which starts with lines such as
+// '#separator:tab' or '#tags column:3'. The columns are numbered from 1, and
+// are 0 if the export does not have them
+type ankiHeader struct {
+	separator                  string
+	html                       bool
+	tags, deck, guid, notetype int
+}
+
+// ankiCards reads the notes of an Anki export. The first two fields of each note
+// are its front and back. The fields may be quoted, and hold html. A cloze note
+// asks for each of its deletions, which are its answer or parts
+func (im *Importer) ankiCards(data []byte) ([]sourceEntry, error) {
+	header := ankiHeader{separator: separatorByName(im.Separator, defaultImportSeparator)}
+	headerLines := 0
+	for bytes.HasPrefix(data, []byte(ankiHeaderPrefix)) {
+		line := data[len(ankiHeaderPrefix):]
+		data = nil
+		if end := bytes.IndexByte(line, '\n'); end >= 0 {
+			line, data = line[:end], line[end+1:]
+		}
+		headerLines++
+		if err := header.set(strings.TrimSpace(string(line))); err != nil {
+			return nil, &SourceError{Line: headerLines, Err: err}
+		}
+	}
+
+	separator, size := utf8.DecodeRuneInString(header.separator)
+	if size != len(header.separator) || separator == '"' || separator == '\n' || separator == '\r' {
+		return nil, fmt.Errorf("the separator of an Anki export must be a single character, not %q", header.separator)
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = separator
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var cards []sourceEntry
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &SourceError{Line: parseErr.Line + headerLines, Column: parseErr.Column, Err: parseErr.Err}
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, column := reader.FieldPos(0)
+		entry := sourceEntry{line: line + headerLines, column: column}
+		entry.questionEntry, entry.err = header.entry(row)
+		cards = append(cards, entry)
+	}
+	return cards, nil
+}
+
+func (header *ankiHeader) set(line string) error {
+	key, value, found := strings.Cut(line, ":")
+	if !found {
+		//a comment rather than a setting
+		return nil
+	}
+	key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+	column := func() (int, error) {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 1 {
+			return 0, fmt.Errorf("invalid column %q of %s", value, key)
+		}
+		return number, nil
+	}
+	var err error
+	switch key {
+	case "separator":
+		header.separator = separatorByName(value, defaultImportSeparator)
+	case "html":
+		header.html, err = strconv.ParseBool(value)
+		if err != nil {
+			err = fmt.Errorf("invalid html setting %q", value)
+		}
+	case "tags column":
+		header.tags, err = column()
+	case "deck column":
+		header.deck, err = column()
+	case "guid column":
+		header.guid, err = column()
+	case "notetype column":
+		header.notetype, err = column()
+	}
+	return err
+}
+
+func (header *ankiHeader) entry(row []string) (questionEntry, error) {
+	var entry questionEntry
+	var fields []string
+	for idx, value := range row {
+		switch idx + 1 {
+		case header.tags:
+			entry.Tags = strings.Fields(value)
+		case header.deck:
+			entry.Category = value
+		case header.guid, header.notetype:
+		default:
+			fields = append(fields, header.text(value))
+		}
+	}
+	if len(fields) == 0 {
+		return entry, errors.New("the note has no fields")
+	}
+
+	entry.Question = fields[0]
+	clozes := ankiCloze.FindAllStringSubmatch(entry.Question, -1)
+	if len(clozes) == 0 {
+		if len(fields) < 2 {
+			return entry, errors.New("the note has no back")
+		}
+		entry.Answer = fields[1]
+		return entry, nil
+	}
+	//the back of a cloze note is extra information, which the quiz has no place for
+	entry.Question = ankiCloze.ReplaceAllStringFunc(entry.Question, func(cloze string) string {
+		if hint := ankiCloze.FindStringSubmatch(cloze)[2]; hint != "" {
+			return "[" + hint + "]"
+		}
+		return clozeBlank
+	})
+	if len(clozes) == 1 {
+		entry.Answer = clozes[0][1]
+		return entry, nil
+	}
+	for _, cloze := range clozes {
+		entry.Parts = append(entry.Parts, cloze[1])
+	}
+	return entry, nil
+}
+
+// text converts a field to plain text, keeping the line breaks of its html
+func (header *ankiHeader) text(field string) string {
+	field = ankiMedia.ReplaceAllString(field, "")
+	if !header.html {
+		return field
+	}
+	field = htmlBreak.ReplaceAllString(field, "\n")
+	field = htmlTag.ReplaceAllString(field, "")
+	field = strings.ReplaceAll(html.UnescapeString(field), "\u00a0", " ")
+	lines := strings.Split(field, "\n")
+	for idx := range lines {
+		lines[idx] = strings.TrimRight(lines[idx], " \t")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writeBank writes the entries as a quiz file of the given format, which can
+// be read back by the QuestionSource of the format
+func writeBank(w io.Writer, format string, entries []questionEntry) error {
+	switch format {
+	case formatCSV:
+		return writeCSVBank(w, entries)
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	case formatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(entries); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case formatTOML:
+		return toml.NewEncoder(w).Encode(struct {
+			Questions []questionEntry `toml:"questions"`
+		}{entries})
+	}
+	return fmt.Errorf("unsupported quiz format %q", format)
+}
+
+// writeCSVBank writes the entries with a header, which only has the columns
+// which are used by the imported cards
+func writeCSVBank(w io.Writer, entries []questionEntry) error {
+	var hasParts, hasCategory, hasTags bool
+	for _, entry := range entries {
+		hasParts = hasParts || len(entry.Parts) > 0
+		hasCategory = hasCategory || entry.Category != ""
+		hasTags = hasTags || len(entry.Tags) > 0
+	}
+	header := []string{csvColumnQuestion, csvColumnAnswer}
+	if hasParts {
+		header = append(header, csvColumnParts)
+	}
+	if hasCategory {
+		header = append(header, csvColumnCategory)
+	}
+	if hasTags {
+		header = append(header, csvColumnTags)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		row := []string{entry.Question, entry.Answer}
+		for _, column := range header[2:] {
+			var err error
+			switch column {
+			case csvColumnParts:
+				row, err = appendCSVList(row, entry.Parts)
+			case csvColumnCategory:
+				row = append(row, entry.Category)
+			case csvColumnTags:
+				row, err = appendCSVList(row, entry.Tags)
+			}
+			if err != nil {
+				return fmt.Errorf("question %q: %v", entry.Question, err)
+			}
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func appendCSVList(row []string, list []string) ([]string, error) {
+	for _, item := range list {
+		if strings.Contains(item, csvListSeparator) {
+			return nil, fmt.Errorf("%q can't be written to a csv file, since it contains %q", item, csvListSeparator)
+		}
+	}
+	return append(row, strings.Join(list, csvListSeparator)), nil
+}
@@ -0,0 +1,114 @@
+package quiz
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const ankiExport = "#separator:tab\n#html:true\n#guid column:1\n#deck column:3\n#tags column:5\n" +
+	"abc\tCapital of <b>France</b>?\tGeography::Europe\tParis&nbsp;\tgeo capitals\n" +
+	"def\t\"Two\nlines\"\tGeography\tyes<br>really [sound:yes.mp3]\t\n" +
+	"ghi\t{{c1::Rome}} is the capital of {{c2::Italy::country}}\tGeography\t\tgeo\n"
+
+func TestImporter_Import_anki(t *testing.T) {
+	importer := Importer{From: ImportAnki, Tags: []string{"imported", "geo"}}
+	questions := importQuestions(t, &importer, ankiExport, formatJSON)
+	if len(questions) != 3 {
+		t.Fatalf("Import(): want 3 questions, got %d", len(questions))
+	}
+
+	first := questions[0]
+	if first.Text != "Capital of France?" || first.Answer != "Paris" || first.Category != "Geography::Europe" {
+		t.Errorf("Import(): want the html to be converted to text, got %+v", first)
+	}
+	if got := strings.Join(first.Tags, " "); got != "geo capitals imported" {
+		t.Errorf("Import(): want the tags of the note followed by the added ones, got %q", got)
+	}
+	if second := questions[1]; second.Text != "Two\nlines" || second.Answer != "yes\nreally" {
+		t.Errorf("Import(): want the line breaks to be kept and the sounds removed, got %q and %q", second.Text, second.Answer)
+	}
+	cloze := questions[2]
+	if cloze.Text != "[...] is the capital of [country]" || strings.Join(cloze.Parts, "|") != "Rome|Italy" {
+		t.Errorf("Import(): want each deletion of a cloze note to be a part, got %q with parts %q", cloze.Text, cloze.Parts)
+	}
+}
+
+func TestImporter_Import_quizlet(t *testing.T) {
+	importer := Importer{From: ImportQuizlet, Separator: " - ", CardSeparator: "semicolon", Category: "Spanish", Reverse: true}
+	questions := importQuestions(t, &importer, "perro - dog; gato - cat;\n", formatYAML)
+	if len(questions) != 2 {
+		t.Fatalf("Import(): want 2 questions, got %d", len(questions))
+	}
+	if q := questions[1]; q.Text != "cat" || q.Answer != "gato" || q.Category != "Spanish" {
+		t.Errorf("Import(): want the reversed card in the category, got %+v", q)
+	}
+
+	importer = Importer{From: ImportQuizlet}
+	if questions = importQuestions(t, &importer, "hola\thello\r\n\r\nsi\tyes\r\n", formatTOML); len(questions) != 2 || questions[1].Answer != "yes" {
+		t.Errorf("Import(): want the cards of the default separators, got %+v", questions)
+	}
+}
+
+// importQuestions imports the cards and reads back the questions of the bank
+func importQuestions(t *testing.T, importer *Importer, export, format string) []Question {
+	t.Helper()
+	var bank bytes.Buffer
+	count, err := importer.Import(strings.NewReader(export), &bank, format)
+	if err != nil {
+		t.Fatalf("Import() received an error: %v", err)
+	}
+	source, _ := NewSource(format, &bank)
+	questions, err := source.Questions()
+	if err != nil {
+		t.Fatalf("Import(): want a valid %s bank, got an error: %v", format, err)
+	}
+	if count != len(questions) {
+		t.Errorf("Import(): want the count of the questions, got %d for %d", count, len(questions))
+	}
+	return questions
+}
+
+func TestImporter_Import_formats(t *testing.T) {
+	importer := Importer{From: ImportAnki}
+	for _, format := range []string{formatCSV, formatJSON, formatYAML, formatTOML} {
+		questions := importQuestions(t, &importer, ankiExport, format)
+		if len(questions) != 3 || questions[0].Category != "Geography::Europe" || len(questions[2].Parts) != 2 {
+			t.Errorf("Import(): want the same questions in %s, got %+v", format, questions)
+		}
+	}
+}
+
+func TestImporter_Import_errors(t *testing.T) {
+	tests := []struct {
+		importer Importer
+		export   string
+		line     int
+	}{
+		{Importer{From: ImportQuizlet}, "hola\thello\nno separator\n", 2},
+		{Importer{From: ImportQuizlet}, "hola\thello\n\n\thello\n", 3},
+		{Importer{From: ImportAnki}, "#separator:comma\n#tags column:x\nq,a\n", 2},
+		{Importer{From: ImportAnki}, "#separator:comma\nq,a\nonly a front\n", 3},
+	}
+	for _, test := range tests {
+		var bank bytes.Buffer
+		_, err := test.importer.Import(strings.NewReader(test.export), &bank, formatCSV)
+		var sourceErr *SourceError
+		if !errors.As(err, &sourceErr) || sourceErr.Line != test.line {
+			t.Errorf("Import(): want an error on line %d for %q, got %v", test.line, test.export, err)
+		}
+		if bank.Len() > 0 {
+			t.Errorf("Import(): want nothing to be written for %q, got %q", test.export, bank.String())
+		}
+	}
+
+	importer := Importer{From: "memrise"}
+	if _, err := importer.Import(strings.NewReader("a\tb\n"), &bytes.Buffer{}, formatCSV); err == nil {
+		t.Errorf("Import(): expected an error for an unknown export format")
+	}
+	importer = Importer{From: ImportQuizlet, Tags: []string{"a|b"}}
+	if _, err := importer.Import(strings.NewReader("a\tb\n"), &bytes.Buffer{}, formatCSV); err == nil {
+		t.Errorf("Import(): expected an error for a tag which can't be written to csv")
+	}
+}
@@ -0,0 +1,61 @@
+package quiz
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+)
+
+// InputReader reads responses line by line from a single goroutine, so that
+// no reads are left behind when a question times out
+type InputReader struct {
+	lines chan string
+	err   error
+}
+
+// NewInputReader starts reading lines from r until it is exhausted or ctx is cancelled
+func NewInputReader(ctx context.Context, r io.Reader) *InputReader {
+	in := &InputReader{lines: make(chan string)}
+
+	go func() {
+		defer close(in.lines)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			select {
+			case <-ctx.Done():
+				return
+			case in.lines <- strings.TrimSpace(scanner.Text()):
+			}
+		}
+		in.err = scanner.Err()
+	}()
+
+	return in
+}
+
+// ReadLine waits for the next line of input. It returns io.EOF once the input
+// is exhausted, or the context error if ctx is done first
+func (in *InputReader) ReadLine(ctx context.Context) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case line, ok := <-in.lines:
+		if !ok {
+			if in.err != nil {
+				return "", in.err
+			}
+			return "", io.EOF
+		}
+		return line, nil
+	}
+}
+
+// Discard drops a line which was already read but is no longer wanted, eg. an
+// answer that arrived just as its question timed out
+func (in *InputReader) Discard() {
+	select {
+	case <-in.lines:
+	default:
+	}
+}
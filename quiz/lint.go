@@ -0,0 +1,257 @@
+package quiz
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LintIssue is a problem found in a question bank
+type LintIssue struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (issue LintIssue) String() string {
+	if issue.Column > 0 {
+		return fmt.Sprintf("line %d, column %d: %s", issue.Line, issue.Column, issue.Message)
+	}
+	return fmt.Sprintf("line %d: %s", issue.Line, issue.Message)
+}
+
+// suspiciousRunes are invisible or look-alike characters which are most likely
+// left behind by copying questions from a document or a web page
+var suspiciousRunes = map[rune]string{
+	'\u00a0': "non-breaking space",
+	'\u200b': "zero width space",
+	'\u200c': "zero width non-joiner",
+	'\u200d': "zero width joiner",
+	'\u2060': "word joiner",
+	'\ufeff': "byte order mark",
+	'\ufffd': "replacement character",
+}
+
+// Lint checks every question of a bank of the given format. Unlike a QuestionSource,
+// it does not stop at the first problem. An error is only returned if the bank
+// can't be read at all, in which case the issues found so far are still returned
+func Lint(format string, r io.Reader) ([]LintIssue, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := lintEncoding(data)
+	source, err := NewSource(format, bytes.NewReader(data))
+	if err != nil {
+		return issues, err
+	}
+	entries, err := source.(entrySource).entries()
+	if err != nil {
+		var srcErr *SourceError
+		if !errors.As(err, &srcErr) {
+			return issues, err
+		}
+		issues = append(issues, LintIssue{Line: srcErr.Line, Column: srcErr.Column, Message: srcErr.Err.Error()})
+		return sortIssues(issues), nil
+	}
+	if _, isCSV := source.(*csvSource); isCSV {
+		var spaceIssues []LintIssue
+		entries, spaceIssues = lintCSVSpace(data, entries)
+		issues = append(issues, spaceIssues...)
+	}
+
+	var (
+		valid     []sourceEntry
+		questions []Question
+	)
+	for _, entry := range entries {
+		issues = append(issues, lintEntry(&entry)...)
+		if entry.err != nil {
+			issues = append(issues, entry.issue(entry.err.Error()))
+			continue
+		}
+		question, err := entry.toQuestion()
+		if err != nil {
+			issues = append(issues, entry.issue(err.Error()))
+			continue
+		}
+		valid = append(valid, entry)
+		questions = append(questions, question)
+	}
+	issues = append(issues, lintDuplicates(valid)...)
+	issues = append(issues, lintAnswerFormats(valid, questions)...)
+	return sortIssues(issues), nil
+}
+
+// lintCSVSpace reads the csv entries again without trimming the whitespace at
+// the start of their fields, so that it is reported along with the trailing
+// whitespace. A quoted field can only follow whitespace when it is trimmed, so
+// the entry is reported on its own and kept as it was read
+func lintCSVSpace(data []byte, entries []sourceEntry) ([]sourceEntry, []LintIssue) {
+	spaced, err := (&csvSource{r: bytes.NewReader(data), keepLeadingSpace: true}).entries()
+	if err != nil || len(spaced) != len(entries) {
+		return entries, nil
+	}
+	var issues []LintIssue
+	for idx := range spaced {
+		if spaced[idx].err != nil && entries[idx].err == nil {
+			issues = append(issues, spaced[idx].issue("quoted field has leading whitespace"))
+			spaced[idx] = entries[idx]
+		}
+	}
+	return spaced, issues
+}
+
+func (entry *sourceEntry) issue(format string, args ...any) LintIssue {
+	return LintIssue{Line: entry.line, Column: entry.column, Message: fmt.Sprintf(format, args...)}
+}
+
+// lintEncoding looks for invalid utf-8, control characters and invisible characters, line by line
+func lintEncoding(data []byte) []LintIssue {
+	var issues []LintIssue
+	for lineIdx, line := range bytes.Split(data, []byte{'\n'}) {
+		line = bytes.TrimSuffix(line, []byte{'\r'})
+		column := 1
+		for len(line) > 0 {
+			r, size := utf8.DecodeRune(line)
+			switch {
+			case r == utf8.RuneError && size == 1:
+				issues = append(issues, LintIssue{Line: lineIdx + 1, Column: column, Message: fmt.Sprintf("invalid utf-8 byte 0x%02x", line[0])})
+			case r == '\ufeff' && lineIdx == 0 && column == 1:
+				issues = append(issues, LintIssue{Line: 1, Column: 1, Message: "file starts with a byte order mark"})
+			case suspiciousRunes[r] != "":
+				issues = append(issues, LintIssue{Line: lineIdx + 1, Column: column, Message: "invisible character: " + suspiciousRunes[r]})
+			case unicode.IsControl(r) && r != '\t':
+				issues = append(issues, LintIssue{Line: lineIdx + 1, Column: column, Message: fmt.Sprintf("control character %U", r)})
+			}
+			line = line[size:]
+			column++
+		}
+	}
+	return issues
+}
+
+// lintEntry finds the problems which the question source silently tolerates
+func lintEntry(entry *sourceEntry) []LintIssue {
+	var issues []LintIssue
+	fields := []struct{ name, value string }{{"question", entry.Question}, {"answer", entry.Answer}}
+	for _, field := range fields {
+		if field.value != "" && strings.TrimSpace(field.value) != field.value {
+			issues = append(issues, entry.issue("%s %q has leading or trailing whitespace", field.name, field.value))
+		}
+		if strings.Contains(strings.TrimSpace(field.value), "  ") {
+			issues = append(issues, entry.issue("%s %q has repeated spaces", field.name, field.value))
+		}
+	}
+
+	lists := []struct {
+		name   string
+		values []string
+	}{{"alias", entry.Aliases}, {"tag", entry.Tags}}
+	for _, list := range lists {
+		for _, value := range list.values {
+			if strings.TrimSpace(value) == "" {
+				issues = append(issues, entry.issue("%s is empty", list.name))
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	answer := strings.TrimSpace(entry.Answer)
+	for _, option := range entry.Options {
+		option = strings.TrimSpace(option)
+		if seen[strings.ToLower(option)] && option != "" {
+			issues = append(issues, entry.issue("option %q is listed more than once", option))
+		}
+		seen[strings.ToLower(option)] = true
+		if option != answer && strings.EqualFold(option, answer) {
+			issues = append(issues, entry.issue("answer %q differs in case from the option %q", answer, option))
+		}
+	}
+	return issues
+}
+
+// lintDuplicates reports questions which are asked more than once, ignoring case and spacing
+func lintDuplicates(entries []sourceEntry) []LintIssue {
+	var (
+		issues []LintIssue
+		first  = make(map[string]*sourceEntry)
+	)
+	for idx := range entries {
+		entry := &entries[idx]
+		key := strings.ToLower(collapseSpace(entry.Question))
+		if original, found := first[key]; found {
+			issues = append(issues, entry.issue("duplicate of the question on line %d", original.line))
+			continue
+		}
+		first[key] = entry
+	}
+	return issues
+}
+
+// lintAnswerFormats reports free text answers which are not numbers in a
+// category where most answers are numbers, or the other way around
+func lintAnswerFormats(entries []sourceEntry, questions []Question) []LintIssue {
+	type answerCount struct{ numeric, text int }
+	counts := make(map[string]*answerCount)
+	isFreeText := func(question *Question) bool {
+		_, isRegex := question.Matcher.(*regexMatcher)
+		return !question.IsMultipleChoice() && !question.IsMultiPart() && !isRegex
+	}
+	isNumeric := func(answer string) bool {
+		_, err := strconv.ParseFloat(answer, 64)
+		return err == nil
+	}
+
+	for idx := range questions {
+		question := &questions[idx]
+		if !isFreeText(question) {
+			continue
+		}
+		count, found := counts[question.CategoryName()]
+		if !found {
+			count = &answerCount{}
+			counts[question.CategoryName()] = count
+		}
+		if isNumeric(question.Answer) {
+			count.numeric++
+		} else {
+			count.text++
+		}
+	}
+
+	var issues []LintIssue
+	for idx := range questions {
+		question := &questions[idx]
+		if !isFreeText(question) {
+			continue
+		}
+		count, numeric := counts[question.CategoryName()], isNumeric(question.Answer)
+		switch {
+		case !numeric && count.numeric > count.text:
+			issues = append(issues, entries[idx].issue("answer %q is not a number, unlike %d other answers in %s",
+				question.Answer, count.numeric, question.CategoryName()))
+		case numeric && count.text > count.numeric:
+			issues = append(issues, entries[idx].issue("answer %q is a number, unlike %d other answers in %s",
+				question.Answer, count.text, question.CategoryName()))
+		}
+	}
+	return issues
+}
+
+func sortIssues(issues []LintIssue) []LintIssue {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Column < issues[j].Column
+	})
+	return issues
+}
@@ -0,0 +1,92 @@
+package quiz
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	content := "question,answer,options,category\n" +
+		"5+5,10,,Maths\n" +
+		"5 + 5,10,,Maths\n" +
+		"5  +  5,10,,Maths\n" +
+		"7*8,fifty six,,Maths\n" +
+		"Capital of France?,paris,London|Paris|London,\n" +
+		"Empty,,,\n" +
+		"9\u200b-3,6,,Maths\n" +
+		"bad\xff,1,,Maths\n"
+
+	issues, err := Lint(formatCSV, strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Lint() received an error: %v", err)
+	}
+
+	want := []struct {
+		line    int
+		message string
+	}{
+		{4, "repeated spaces"},
+		{4, "duplicate of the question on line 3"},
+		{5, "is not a number"},
+		{6, "differs in case"},
+		{6, "listed more than once"},
+		{7, "answer is empty"},
+		{8, "zero width space"},
+		{9, "invalid utf-8"},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("Lint(): want %d issues, got %d: %v", len(want), len(issues), issues)
+	}
+	for idx, issue := range issues {
+		if issue.Line != want[idx].line || !strings.Contains(issue.Message, want[idx].message) {
+			t.Errorf("issues[%d]: want %q on line %d, got %s", idx, want[idx].message, want[idx].line, issue)
+		}
+	}
+}
+
+func TestLint_csvWhitespace(t *testing.T) {
+	content := "question,answer\n" +
+		"5+5, 10\n" +
+		" 1+1,2\n" +
+		"2+2,4 \n" +
+		"3+3, \"6\"\n" +
+		"4+4,8\n"
+
+	issues, err := Lint(formatCSV, strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Lint() received an error: %v", err)
+	}
+	want := []struct {
+		line    int
+		message string
+	}{
+		{2, `answer " 10" has leading or trailing whitespace`},
+		{3, `question " 1+1" has leading or trailing whitespace`},
+		{4, `answer "4 " has leading or trailing whitespace`},
+		{5, "quoted field has leading whitespace"},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("Lint(): want %d issues, got %d: %v", len(want), len(issues), issues)
+	}
+	for idx, issue := range issues {
+		if issue.Line != want[idx].line || !strings.Contains(issue.Message, want[idx].message) {
+			t.Errorf("issues[%d]: want %q on line %d, got %s", idx, want[idx].message, want[idx].line, issue)
+		}
+	}
+
+	//the bank is still read with the whitespace trimmed
+	source, _ := NewSource(formatCSV, strings.NewReader(content))
+	if questions, err := source.Questions(); err != nil || len(questions) != 5 || questions[3].Answer != "6" {
+		t.Errorf("Questions(): want the questions without the whitespace, got %+v (%v)", questions, err)
+	}
+}
+
+func TestLint_syntaxError(t *testing.T) {
+	issues, err := Lint(formatJSON, strings.NewReader("[\n  {\"question\": \"5+5\", \"answer\": 10}\n  {\"question\": \"1+1\"}\n]"))
+	if err != nil {
+		t.Fatalf("Lint() received an error: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Line != 3 {
+		t.Errorf("Lint(): want a single issue on line 3, got %v", issues)
+	}
+}
@@ -0,0 +1,313 @@
+package quiz
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+	"html"
+	"strings"
+)
+
+const (
+	// terminalStyle and htmlStyle are the chroma styles of the code blocks, the
+	// first one for a dark terminal and the second one for the light web pages
+	terminalStyle = "monokai"
+	htmlStyle     = "github"
+	codeIndent    = "    "
+
+	ansiBold      = "\x1b[1m"
+	ansiBoldOff   = "\x1b[22m"
+	ansiItalic    = "\x1b[3m"
+	ansiItalicOff = "\x1b[23m"
+	ansiCode      = "\x1b[36m"
+	ansiCodeOff   = "\x1b[39m"
+	ansiReset     = "\x1b[0m"
+)
+
+// markdown is the parser and HTML renderer of the questions. Raw HTML in a
+// question is left out, so that a quiz file can't add scripts to the web pages
+var markdown = goldmark.New(goldmark.WithRendererOptions(
+	renderer.WithNodeRenderers(util.Prioritized(codeBlockRenderer{}, 100)),
+))
+
+// Render is the text of the question as shown in a terminal. Markdown is laid
+// out as plain text, and only uses colors, eg. to highlight code, if color is set
+func (question *Question) Render(color bool) string {
+	if !question.Markdown {
+		return question.Text
+	}
+	source := []byte(question.Text)
+	r := terminalRenderer{source: source, color: color}
+	lines := r.blocks(markdown.Parser().Parse(text.NewReader(source)), true)
+	return strings.Join(lines, "\n")
+}
+
+// HTML is the text of the question as HTML. A question which is not Markdown is
+// escaped, keeping its line breaks
+func (question *Question) HTML() string {
+	if question.Markdown {
+		var buf bytes.Buffer
+		if err := markdown.Convert([]byte(question.Text), &buf); err == nil {
+			return buf.String()
+		}
+	}
+	lines := strings.Split(html.EscapeString(question.Text), "\n")
+	return "<p>" + strings.Join(lines, "<br>\n") + "</p>\n"
+}
+
+// Summary is the first line of the question, for reports and lists of questions
+func (question *Question) Summary() string {
+	return firstLine(question.Render(false))
+}
+
+// firstLine shortens a text of several lines to its first one
+func firstLine(text string) string {
+	text = strings.TrimSpace(text)
+	if first, _, multiline := strings.Cut(text, "\n"); multiline {
+		return strings.TrimSpace(first) + " ..."
+	}
+	return text
+}
+
+// terminalRenderer renders the blocks of a Markdown document as lines of text
+type terminalRenderer struct {
+	source []byte
+	color  bool
+}
+
+// blocks renders the children of parent, separated by a blank line unless they
+// are the items of a tight list
+func (r *terminalRenderer) blocks(parent ast.Node, separate bool) []string {
+	var lines []string
+	for node := parent.FirstChild(); node != nil; node = node.NextSibling() {
+		block := r.block(node)
+		if len(block) == 0 {
+			continue
+		}
+		if len(lines) > 0 && separate {
+			lines = append(lines, "")
+		}
+		lines = append(lines, block...)
+	}
+	return lines
+}
+
+func (r *terminalRenderer) block(node ast.Node) []string {
+	switch node := node.(type) {
+	case *ast.Heading:
+		return strings.Split(r.style(ansiBold, r.inline(node), ansiBoldOff), "\n")
+	case *ast.Paragraph, *ast.TextBlock:
+		return strings.Split(r.inline(node), "\n")
+	case *ast.FencedCodeBlock:
+		return r.code(r.lines(node), string(node.Language(r.source)))
+	case *ast.CodeBlock:
+		return r.code(r.lines(node), "")
+	case *ast.List:
+		var lines []string
+		number := node.Start
+		for item := node.FirstChild(); item != nil; item = item.NextSibling() {
+			marker := "- "
+			if node.IsOrdered() {
+				marker = fmt.Sprintf("%d. ", number)
+				number++
+			}
+			if len(lines) > 0 && !node.IsTight {
+				lines = append(lines, "")
+			}
+			lines = append(lines, indent(r.blocks(item, !node.IsTight), marker)...)
+		}
+		return lines
+	case *ast.Blockquote:
+		lines := r.blocks(node, true)
+		for idx := range lines {
+			lines[idx] = "| " + lines[idx]
+		}
+		return lines
+	case *ast.ThematicBreak:
+		return []string{strings.Repeat("-", 20)}
+	case *ast.HTMLBlock:
+		//raw HTML is left out, as it is from the web pages
+		return nil
+	}
+	return strings.Split(r.inline(node), "\n")
+}
+
+// indent prefixes the first line with the marker of a list item, and the other
+// lines with as many spaces
+func indent(lines []string, marker string) []string {
+	if len(lines) == 0 {
+		return []string{strings.TrimSpace(marker)}
+	}
+	padding := strings.Repeat(" ", len(marker))
+	for idx := range lines {
+		switch {
+		case idx == 0:
+			lines[idx] = marker + lines[idx]
+		case lines[idx] != "":
+			lines[idx] = padding + lines[idx]
+		}
+	}
+	return lines
+}
+
+func (r *terminalRenderer) lines(node ast.Node) []string {
+	var lines []string
+	for idx := 0; idx < node.Lines().Len(); idx++ {
+		line := node.Lines().At(idx)
+		lines = append(lines, strings.TrimRight(string(line.Value(r.source)), "\r\n"))
+	}
+	return lines
+}
+
+// code indents a block of code, and highlights its syntax if colors are used.
+// The language is guessed if it is not given
+func (r *terminalRenderer) code(lines []string, language string) []string {
+	plain := make([]string, len(lines))
+	for idx, line := range lines {
+		//tabs would make the width of the lines depend on the terminal
+		plain[idx] = codeIndent + strings.ReplaceAll(line, "\t", codeIndent)
+	}
+	if !r.color || len(lines) == 0 {
+		return plain
+	}
+
+	source := strings.ReplaceAll(strings.Join(lines, "\n"), "\t", codeIndent)
+	tokens, err := codeLexer(language, source).Tokenise(nil, source)
+	if err != nil {
+		return plain
+	}
+	//each line is highlighted on its own, so that it does not depend on the ones before
+	var highlighted []string
+	for _, line := range chroma.SplitTokensIntoLines(tokens.Tokens()) {
+		if last := len(line) - 1; last >= 0 {
+			line[last].Value = strings.TrimSuffix(line[last].Value, "\n")
+		}
+		var buf bytes.Buffer
+		if err := formatters.TTY256.Format(&buf, styles.Get(terminalStyle), chroma.Literator(line...)); err != nil {
+			return plain
+		}
+		highlighted = append(highlighted, codeIndent+buf.String()+ansiReset)
+	}
+	return highlighted
+}
+
+func codeLexer(language, source string) chroma.Lexer {
+	lexer := lexers.Get(language)
+	if lexer == nil {
+		lexer = lexers.Analyse(source)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	return chroma.Coalesce(lexer)
+}
+
+// inline renders the inline children of node, keeping its line breaks
+func (r *terminalRenderer) inline(node ast.Node) string {
+	var sb strings.Builder
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		switch child := child.(type) {
+		case *ast.Text:
+			value := child.Segment.Value(r.source)
+			if !child.IsRaw() {
+				value = util.UnescapePunctuations(util.ResolveNumericReferences(util.ResolveEntityNames(value)))
+			}
+			sb.Write(value)
+			if child.SoftLineBreak() || child.HardLineBreak() {
+				sb.WriteString("\n")
+			}
+		case *ast.String:
+			sb.Write(child.Value)
+		case *ast.CodeSpan:
+			if r.color {
+				sb.WriteString(ansiCode + r.raw(child) + ansiCodeOff)
+			} else {
+				sb.WriteString("`" + r.raw(child) + "`")
+			}
+		case *ast.Emphasis:
+			if child.Level > 1 {
+				sb.WriteString(r.style(ansiBold, r.inline(child), ansiBoldOff))
+			} else {
+				sb.WriteString(r.style(ansiItalic, r.inline(child), ansiItalicOff))
+			}
+		case *ast.Link:
+			label, destination := r.inline(child), string(child.Destination)
+			sb.WriteString(label)
+			if label != destination {
+				sb.WriteString(" (" + destination + ")")
+			}
+		case *ast.AutoLink:
+			sb.Write(child.URL(r.source))
+		case *ast.Image:
+			fmt.Fprintf(&sb, "[image: %s] (%s)", r.inline(child), child.Destination)
+		case *ast.RawHTML:
+			//raw HTML is left out, as it is from the web pages
+		default:
+			sb.WriteString(r.inline(child))
+		}
+	}
+	return sb.String()
+}
+
+// raw is the text of a code span, in which nothing is escaped
+func (r *terminalRenderer) raw(node ast.Node) string {
+	var sb strings.Builder
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if t, ok := child.(*ast.Text); ok {
+			sb.Write(t.Segment.Value(r.source))
+		}
+	}
+	return sb.String()
+}
+
+func (r *terminalRenderer) style(on, text, off string) string {
+	if !r.color || text == "" {
+		return text
+	}
+	return on + text + off
+}
+
+// codeBlockRenderer highlights the code blocks of the HTML with inline styles,
+// so that the pages need no stylesheet for it
+type codeBlockRenderer struct{}
+
+func (codeBlockRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, renderCodeBlock)
+	reg.Register(ast.KindCodeBlock, renderCodeBlock)
+}
+
+func renderCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	var (
+		sb       strings.Builder
+		language string
+	)
+	for idx := 0; idx < node.Lines().Len(); idx++ {
+		line := node.Lines().At(idx)
+		sb.Write(line.Value(source))
+	}
+	if fenced, ok := node.(*ast.FencedCodeBlock); ok {
+		language = string(fenced.Language(source))
+	}
+	code := sb.String()
+	tokens, err := codeLexer(language, code).Tokenise(nil, code)
+	if err == nil {
+		err = chromahtml.New(chromahtml.WithClasses(false)).Format(w, styles.Get(htmlStyle), tokens)
+	}
+	if err != nil {
+		//the code is still shown, just without highlighting
+		_, _ = fmt.Fprintf(w, "<pre><code>%s</code></pre>\n", html.EscapeString(code))
+	}
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,90 @@
+package quiz
+
+import (
+	"strings"
+	"testing"
+)
+
+const markdownQuestion = "What does this **print**?\n\n```go\nfunc main() {\n\tfmt.Println(len(\"h\u00e9llo\"))\n}\n```\n\n" +
+	"- count *bytes*\n- not `runes`\n\n<script>alert(1)</script>"
+
+func TestQuestion_Render(t *testing.T) {
+	question := Question{Text: markdownQuestion, Markdown: true}
+	want := "What does this print?\n\n" +
+		"    func main() {\n        fmt.Println(len(\"h\u00e9llo\"))\n    }\n\n" +
+		"- count bytes\n- not `runes`"
+	if got := question.Render(false); got != want {
+		t.Errorf("Render(false): want %q, got %q", want, got)
+	}
+
+	colored := question.Render(true)
+	if !strings.Contains(colored, "\x1b[") || !strings.Contains(colored, "Println") {
+		t.Errorf("Render(true): want the code to be highlighted, got %q", colored)
+	}
+	if lines := strings.Split(colored, "\n"); len(lines) != len(strings.Split(want, "\n")) {
+		t.Errorf("Render(true): want as many lines as without colors, got %q", colored)
+	}
+
+	plain := Question{Text: "5*5*2?"}
+	if got := plain.Render(true); got != "5*5*2?" {
+		t.Errorf("Render(): want a question which is not markdown as it is, got %q", got)
+	}
+}
+
+func TestQuestion_HTML(t *testing.T) {
+	question := Question{Text: markdownQuestion, Markdown: true}
+	got := question.HTML()
+	for _, want := range []string{"<strong>print</strong>", "<pre", "Println", "<li>count <em>bytes</em></li>", "<code>runes</code>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("HTML(): want %q in %q", want, got)
+		}
+	}
+	if strings.Contains(got, "<script>") {
+		t.Errorf("HTML(): want raw html to be left out, got %q", got)
+	}
+
+	plain := Question{Text: "Is 1 < 2?\nOr 2*2 > 3?"}
+	if got, want := plain.HTML(), "<p>Is 1 &lt; 2?<br>\nOr 2*2 &gt; 3?</p>\n"; got != want {
+		t.Errorf("HTML(): want %q, got %q", want, got)
+	}
+}
+
+func TestQuestion_Summary(t *testing.T) {
+	tests := map[string]Question{
+		"What does this print? ...": {Text: markdownQuestion, Markdown: true},
+		"What is **bold**?":         {Text: "What is **bold**?"},
+		"Line one ...":              {Text: "Line one\nLine two"},
+	}
+	for want, question := range tests {
+		if got := question.Summary(); got != want {
+			t.Errorf("Summary(): want %q, got %q", want, got)
+		}
+	}
+}
+
+func TestQuestion_Prompt_markdown(t *testing.T) {
+	question := Question{Text: "Which keyword?\n\n    go func()", Markdown: true, Options: []string{"go", "defer"}}
+	want := "Question #2:\nWhich keyword?\n\n    go func()\n  A) go\n  B) defer\n> "
+	if got := question.Prompt(2, false); got != want {
+		t.Errorf("Prompt(): want %q, got %q", want, got)
+	}
+}
+
+func TestQuestionSources_markdown(t *testing.T) {
+	source, _ := NewSource(formatCSV, strings.NewReader("question,answer,markdown\n\"Is `x` set?\n\n- yes\n- no\",yes,true\nPlain?,yes,\n"))
+	questions, err := source.Questions()
+	if err != nil {
+		t.Fatalf("Questions() received an error: %v", err)
+	}
+	if !questions[0].Markdown || questions[1].Markdown {
+		t.Errorf("Questions(): want only the first question to be markdown, got %v and %v", questions[0].Markdown, questions[1].Markdown)
+	}
+	if !strings.Contains(questions[0].Text, "\n- yes\n") {
+		t.Errorf("Questions(): want the lines of the question to be kept, got %q", questions[0].Text)
+	}
+
+	source, _ = NewSource(formatCSV, strings.NewReader("question,answer,markdown\nPlain?,yes,maybe\n"))
+	if _, err := source.Questions(); err == nil {
+		t.Errorf("Questions(): expected an error for an invalid markdown column")
+	}
+}
@@ -0,0 +1,159 @@
+package quiz
+
+import (
+	"fmt"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	matchExact   = "exact"
+	matchNoCase  = "nocase"
+	matchSpace   = "space"
+	matchNumeric = "numeric"
+	matchRegex   = "regex"
+	// matchNFKC applies the compatibility normalization, which also folds ligatures, widths and the like
+	matchNFKC = "nfkc"
+	// matchNoAccent ignores accents and other diacritics, eg. an accented e matches a plain e
+	matchNoAccent = "noaccent"
+	// matchWidth folds full-width and half-width forms, eg. full-width latin letters
+	// match the ASCII ones and half-width katakana match the usual ones
+	matchWidth = "width"
+
+	DefaultMatch = matchExact
+)
+
+// Matcher decides if a response is an acceptable answer to a question
+type Matcher interface {
+	Match(answer, response string) bool
+}
+
+// textMatcher compares answers after normalising both sides of the comparison.
+// Both sides are always in NFC form, so that composed and decomposed characters match
+type textMatcher struct {
+	normalizers []func(string) string
+}
+
+type numericMatcher struct {
+	tolerance float64
+}
+
+// regexMatcher matches the response against a pattern. If no pattern is
+// given, the answer itself is used as the pattern
+type regexMatcher struct {
+	pattern *regexp.Regexp
+}
+
+// ParseMatcher creates a Matcher from a spec in the form 'name[:arg]'. The text
+// strategies (exact, nocase, space, nfkc, noaccent, width) may be combined with
+// a '+', eg. 'nocase+space'
+func ParseMatcher(spec string) (Matcher, error) {
+	spec = strings.TrimSpace(spec)
+	name, arg, hasArg := strings.Cut(spec, ":")
+
+	switch strings.ToLower(name) {
+	case matchRegex:
+		if !hasArg {
+			return &regexMatcher{}, nil
+		}
+		pattern, err := compileAnchored(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex matcher: %v", err)
+		}
+		return &regexMatcher{pattern: pattern}, nil
+	case matchNumeric:
+		var tolerance float64
+		if hasArg {
+			var err error
+			if tolerance, err = strconv.ParseFloat(arg, 64); err != nil || tolerance < 0 {
+				return nil, fmt.Errorf("invalid numeric tolerance %q", arg)
+			}
+		}
+		return &numericMatcher{tolerance: tolerance}, nil
+	}
+
+	var matcher textMatcher
+	for _, name := range strings.Split(spec, "+") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case matchExact:
+		case matchNoCase:
+			matcher.normalizers = append(matcher.normalizers, strings.ToLower)
+		case matchSpace:
+			matcher.normalizers = append(matcher.normalizers, collapseSpace)
+		case matchNFKC:
+			matcher.normalizers = append(matcher.normalizers, norm.NFKC.String)
+		case matchNoAccent:
+			matcher.normalizers = append(matcher.normalizers, removeAccents)
+		case matchWidth:
+			matcher.normalizers = append(matcher.normalizers, foldWidth)
+		case matchNumeric, matchRegex:
+			return nil, fmt.Errorf("the %s matcher cannot be combined with others", name)
+		default:
+			return nil, fmt.Errorf("unknown matcher %q", name)
+		}
+	}
+	return &matcher, nil
+}
+
+func (m *textMatcher) Match(answer, response string) bool {
+	answer, response = norm.NFC.String(answer), norm.NFC.String(response)
+	for _, normalize := range m.normalizers {
+		answer, response = normalize(answer), normalize(response)
+	}
+	return answer == response
+}
+
+func (m *numericMatcher) Match(answer, response string) bool {
+	want, err := strconv.ParseFloat(strings.TrimSpace(answer), 64)
+	if err != nil {
+		return false
+	}
+	//full-width digits are as good as any other
+	got, err := strconv.ParseFloat(strings.TrimSpace(width.Narrow.String(response)), 64)
+	if err != nil {
+		return false
+	}
+	return math.Abs(want-got) <= m.tolerance
+}
+
+func (m *regexMatcher) Match(answer, response string) bool {
+	pattern := m.pattern
+	if pattern == nil {
+		var err error
+		if pattern, err = compileAnchored(answer); err != nil {
+			return false
+		}
+	}
+	return pattern.MatchString(norm.NFC.String(response))
+}
+
+// compileAnchored compiles a pattern which has to match the whole response
+func compileAnchored(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// removeAccents strips the combining marks from the decomposed form of s
+func removeAccents(s string) string {
+	stripped, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), s)
+	if err != nil {
+		return s
+	}
+	return stripped
+}
+
+// foldWidth folds the width of s. Half-width voiced marks become combining
+// marks, which are composed again
+func foldWidth(s string) string {
+	return norm.NFC.String(width.Fold.String(s))
+}
@@ -0,0 +1,69 @@
+package quiz
+
+import "testing"
+
+func TestParseMatcher(t *testing.T) {
+	tests := []struct {
+		spec, answer, response string
+		want                   bool
+	}{
+		{"exact", "Paris", "Paris", true},
+		{"exact", "Paris", "paris", false},
+		{"nocase", "Paris", "paris", true},
+		{"space", "New  York", "New York", true},
+		{"space", "New York", "new york", false},
+		{"nocase+space", "New  York", "new york", true},
+		{"numeric", "0.5", ".5", true},
+		{"numeric", "0.5", "0.51", false},
+		{"numeric:0.01", "3.14", "3.141", true},
+		{"numeric", "10", "ten", false},
+		{"regex", "colou?r", "color", true},
+		{"regex", "colou?r", "colours", false},
+		{"regex:[Pp]aris", "Paris", "paris", true},
+		//composed and decomposed characters always match
+		{"exact", "caf\u00e9", "cafe\u0301", true},
+		{"exact", "caf\u00e9", "cafe", false},
+		{"noaccent", "caf\u00e9", "cafe", true},
+		{"nocase+noaccent", "\u00c9t\u00e9", "ete", true},
+		{"width", "Paris", "\uff30\uff41\uff52\uff49\uff53", true},
+		{"width", "\u30d1\u30ea", "\uff8a\uff9f\uff98", true},
+		{"exact", "\u30d1\u30ea", "\uff8a\uff9f\uff98", false},
+		{"nfkc", "fi", "\ufb01", true},
+		{"numeric", "10", "\uff11\uff10", true},
+	}
+
+	for _, tt := range tests {
+		matcher, err := ParseMatcher(tt.spec)
+		if err != nil {
+			t.Errorf("ParseMatcher(%q) received an error: %v", tt.spec, err)
+			continue
+		}
+		if got := matcher.Match(tt.answer, tt.response); got != tt.want {
+			t.Errorf("%s.Match(%q, %q): want %t, got %t", tt.spec, tt.answer, tt.response, tt.want, got)
+		}
+	}
+
+	for _, spec := range []string{"fuzzy", "numeric:-1", "regex:(", "nocase+numeric"} {
+		if _, err := ParseMatcher(spec); err == nil {
+			t.Errorf("ParseMatcher(%q): expected an error", spec)
+		}
+	}
+}
+
+func TestQuizRecord_isCorrect(t *testing.T) {
+	exact, _ := ParseMatcher(matchExact)
+	nocase, _ := ParseMatcher(matchNoCase)
+
+	record := Record{Question: Question{Answer: "United States", Aliases: []string{"USA", "US"}}, Response: "usa"}
+	if record.IsCorrect(exact) {
+		t.Errorf("IsCorrect(exact): want false for response %q", record.Response)
+	}
+	if !record.IsCorrect(nocase) {
+		t.Errorf("IsCorrect(nocase): want true for response %q", record.Response)
+	}
+
+	record.Matcher = exact
+	if record.IsCorrect(nocase) {
+		t.Errorf("IsCorrect(nocase): the question's own matcher should override the default")
+	}
+}
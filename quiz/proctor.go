@@ -0,0 +1,228 @@
+package quiz
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// The kinds of events in the log of a proctored session
+const (
+	EventStart        = "start"
+	EventResume       = "resume"
+	EventQuestion     = "question"
+	EventSubmit       = "submit"
+	EventTimeout      = "timeout"
+	EventPause        = "pause"
+	EventFocusLost    = "focus_lost"
+	EventFocusGained  = "focus_gained"
+	EventEnd          = "end"
+	algorithmHMAC     = "hmac-sha256"
+	algorithmEd25519  = "ed25519"
+	minHMACKeyLength  = 16
+	pemPrivateKeyType = "PRIVATE KEY"
+	pemPublicKeyType  = "PUBLIC KEY"
+)
+
+// Event is an entry in the log of a proctored session
+type Event struct {
+	Time time.Time `json:"time"`
+	Kind string    `json:"kind"`
+	// Question is the number of the question the event happened on, if any
+	Question int    `json:"question,omitempty"`
+	Text     string `json:"text,omitempty"`
+}
+
+// EventLog is the timestamped log of a proctored session. It is safe for
+// concurrent use, and a nil log records nothing
+type EventLog struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// NewEventLog continues the log of a session which was paused
+func NewEventLog(events []Event) *EventLog {
+	return &EventLog{events: append([]Event(nil), events...)}
+}
+
+// Add records an event which happened now
+func (log *EventLog) Add(kind string, question int, text string) {
+	if log == nil {
+		return
+	}
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	log.events = append(log.events, Event{Time: time.Now().UTC(), Kind: kind, Question: question, Text: text})
+}
+
+// Events is a copy of the events logged so far
+func (log *EventLog) Events() []Event {
+	if log == nil {
+		return nil
+	}
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	return append([]Event(nil), log.events...)
+}
+
+// Result is the outcome of a proctored session, which is signed so that any
+// change to it can be detected
+type Result struct {
+	Quiz     string    `json:"quiz"`
+	User     string    `json:"user"`
+	Seed     int64     `json:"seed"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Report   *Report   `json:"report"`
+	Events   []Event   `json:"events"`
+}
+
+// Key signs and verifies the results of proctored sessions. It is either a
+// secret shared for HMAC-SHA256, or an ed25519 key. An ed25519 public key only verifies
+type Key struct {
+	algorithm string
+	secret    []byte
+	private   ed25519.PrivateKey
+	public    ed25519.PublicKey
+}
+
+// ParseKey reads a key file. An ed25519 key is in PEM form, as a PKCS #8 private
+// key or a PKIX public key, eg. from 'openssl genpkey -algorithm ed25519'. Any
+// other content is the secret of an HMAC key
+func ParseKey(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		secret := bytes.TrimSpace(data)
+		if len(secret) < minHMACKeyLength {
+			return nil, fmt.Errorf("the HMAC secret must be at least %d bytes long", minHMACKeyLength)
+		}
+		return &Key{algorithm: algorithmHMAC, secret: secret}, nil
+	}
+
+	switch block.Type {
+	case pemPrivateKeyType:
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %v", err)
+		}
+		private, ok := parsed.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("the private key is not an ed25519 key")
+		}
+		return &Key{algorithm: algorithmEd25519, private: private, public: private.Public().(ed25519.PublicKey)}, nil
+	case pemPublicKeyType:
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %v", err)
+		}
+		public, ok := parsed.(ed25519.PublicKey)
+		if !ok {
+			return nil, errors.New("the public key is not an ed25519 key")
+		}
+		return &Key{algorithm: algorithmEd25519, public: public}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", block.Type)
+}
+
+// CanSign checks if the key can sign, which an ed25519 public key can't
+func (key *Key) CanSign() bool {
+	return key.algorithm == algorithmHMAC || key.private != nil
+}
+
+// Signature is the signature of a file, along with the algorithm which made it
+type Signature struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"value"`
+}
+
+// Sign signs the data
+func (key *Key) Sign(data []byte) (*Signature, error) {
+	var value []byte
+	switch {
+	case key.algorithm == algorithmHMAC:
+		mac := hmac.New(sha256.New, key.secret)
+		mac.Write(data)
+		value = mac.Sum(nil)
+	case key.private != nil:
+		value = ed25519.Sign(key.private, data)
+	default:
+		return nil, errors.New("a public key can't sign, only verify")
+	}
+	return &Signature{Algorithm: key.algorithm, Value: base64.StdEncoding.EncodeToString(value)}, nil
+}
+
+// Verify checks that the signature was made for the data with this key
+func (key *Key) Verify(data []byte, signature *Signature) error {
+	if signature == nil {
+		return errors.New("the file is not signed")
+	}
+	if signature.Algorithm != key.algorithm {
+		return fmt.Errorf("the file is signed with %s rather than %s", signature.Algorithm, key.algorithm)
+	}
+	value, err := base64.StdEncoding.DecodeString(signature.Value)
+	if err != nil {
+		return errors.New("invalid signature")
+	}
+	valid := false
+	if key.algorithm == algorithmHMAC {
+		mac := hmac.New(sha256.New, key.secret)
+		mac.Write(data)
+		valid = hmac.Equal(value, mac.Sum(nil))
+	} else {
+		valid = ed25519.Verify(key.public, data, value)
+	}
+	if !valid {
+		return errors.New("the signature does not match, the file was changed or signed with another key")
+	}
+	return nil
+}
+
+// signedResult is the result file of a proctored session. The signature is
+// made for the compact JSON of the result, so only a change to its whitespace
+// is allowed
+type signedResult struct {
+	Result    json.RawMessage `json:"result"`
+	Signature *Signature      `json:"signature"`
+}
+
+// SignResult encodes the result along with its signature
+func SignResult(result *Result, key *Key) ([]byte, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := key.Sign(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(&signedResult{Result: data, Signature: signature}, "", "  ")
+}
+
+// VerifyResult checks the signature of a result file before decoding it
+func VerifyResult(data []byte, key *Key) (*Result, error) {
+	var signed signedResult
+	if err := json.Unmarshal(data, &signed); err != nil || signed.Result == nil {
+		return nil, errors.New("not a result file")
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, signed.Result); err != nil {
+		return nil, err
+	}
+	if err := key.Verify(compact.Bytes(), signed.Signature); err != nil {
+		return nil, err
+	}
+	var result Result
+	if err := json.Unmarshal(signed.Result, &result); err != nil {
+		return nil, fmt.Errorf("invalid result: %v", err)
+	}
+	return &result, nil
+}
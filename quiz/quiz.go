@@ -1,135 +1,132 @@
-package main
+// Package quiz loads quizzes from csv, json, yaml or toml files, runs them as
+// timed sessions and scores the responses
+package quiz
 
 import (
-	"encoding/csv"
-	"flag"
 	"fmt"
-	"io"
-	"log"
 	"math/rand"
 	"os"
-	"strings"
 	"time"
 )
 
-type QuizRecord struct {
-	question string
-	answer   string
-	response string
+// Question is a single question of a quiz as it appears in the quiz file
+type Question struct {
+	Text    string
+	Answer  string
+	Options []string
+	Aliases []string
+	// Parts are the answers to a multi-part question, each earning a share of its points
+	Parts []string
+	// Points is the weight of the question in the weighted score, 1 if not set
+	Points   float64
+	Category string
+	Tags     []string
+	// Difficulty is used by the Adaptive order, 0 if not set
+	Difficulty Difficulty
+	// Hint is revealed on request during a session, at a cost
+	Hint string
+	// Markdown questions may have code blocks, lists and several lines of text. See Question.Render
+	Markdown bool
+	// Code is set for questions which are answered with Go code, and scored by
+	// its hidden tests. The answer is then an example solution
+	Code *CodeTests
+	// Translations are keyed by language, eg. 'fr' or 'ja'. See Quiz.Translate
+	Translations map[string]Translation
+	// Matcher overrides the default matcher of the quiz for this question
+	Matcher Matcher
+	// TimeLimit overrides the default time limit per question
+	TimeLimit time.Duration
 }
 
-const (
-	defaultQuizFile = "problems.csv"
-	defaultDuration = 30
-	defaultShuffle  = false
-)
-
-func main() {
-	var quizPath string
-	var timeLimit int
-	var shuffleQuiz bool
+// Record is a question which is being asked, along with its response
+type Record struct {
+	Question
+	Response string
+	Asked    bool
+	Answered bool
+	Elapsed  time.Duration
+	// Skips is the number of times the question was skipped to be answered later
+	Skips int
+	// Hinted is set once the hint was revealed, which costs the HintCost share of the points
+	Hinted   bool
+	HintCost float64
+	// Passed is the number of tests of a code question which the response passed
+	Passed int
+}
 
-	flag.StringVar(&quizPath, "quiz", defaultQuizFile, "A csv file in the format of 'question,answer'")
-	flag.IntVar(&timeLimit, "duration", defaultDuration, "A time limit for the quiz, in seconds")
-	flag.BoolVar(&shuffleQuiz, "shuffle", defaultShuffle, "Shuffle the quiz questions?")
-	flag.Parse()
+// Quiz is a list of questions loaded from a QuestionSource
+type Quiz struct {
+	Questions []Question
+}
 
-	file, err := os.Open(quizPath)
+// Load reads a quiz from the file at path. The format is detected from the
+// file extension unless it is given explicitly
+func Load(path, format string) (*Quiz, error) {
+	format, err := SourceFormat(path, format)
 	if err != nil {
-		log.Printf("Failed to open file %s\n", quizPath)
-		log.Fatal(err)
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %v", path, err)
 	}
 
 	//goland:noinspection GoUnhandledErrorResult
 	defer file.Close()
-	records := readQuestions(file)
-
-	if shuffleQuiz {
-		shuffleQuestions(records)
+	source, err := NewSource(format, file)
+	if err != nil {
+		return nil, err
 	}
+	return FromSource(source)
+}
 
-	fmt.Printf("Welcome to Quizbot. Please answer to the best of your knowledge\n")
-	fmt.Printf("Press enter to start...\n")
-	//goland:noinspection GoUnhandledErrorResult
-	fmt.Scanln()
-
-	var (
-		correctResp, totalQuestions = 0, len(records)
-	)
-
-	for record := range startQuiz(records, time.Duration(timeLimit)*time.Second) {
-		if record == nil {
-			break
-		}
-		if record.response == record.answer {
-			correctResp++
-		}
+// FromSource reads a quiz from any QuestionSource
+func FromSource(source QuestionSource) (*Quiz, error) {
+	questions, err := source.Questions()
+	if err != nil {
+		return nil, err
 	}
-
-	fmt.Printf("\nThanks for taking the quiz. You scored %d/%d = %.1f%%\n", correctResp, totalQuestions, (float64)(correctResp)/(float64)(totalQuestions)*100)
+	return &Quiz{Questions: questions}, nil
 }
 
-func readQuestions(csvFile *os.File) []QuizRecord {
-	var (
-		records    []QuizRecord
-		quizReader = csv.NewReader(csvFile)
-	)
-	quizReader.FieldsPerRecord = 2
-	quizReader.TrimLeadingSpace = true
-	quizReader.ReuseRecord = true
-
-	for {
-		record, err := quizReader.Read()
-		if err == io.EOF {
-			break
-		}
-
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		question, answer := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
-		records = append(records, QuizRecord{question: question, answer: answer})
+// Records returns a fresh set of records to ask the questions of the quiz. The
+// records can be shuffled without affecting the quiz or any other set of records
+func (quiz *Quiz) Records() []Record {
+	records := make([]Record, len(quiz.Questions))
+	for idx, question := range quiz.Questions {
+		question.Options = append([]string(nil), question.Options...)
+		records[idx] = Record{Question: question}
 	}
-
 	return records
 }
 
-func shuffleQuestions(quizRecords []QuizRecord) {
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(quizRecords), func(i, j int) {
-		quizRecords[i], quizRecords[j] = quizRecords[j], quizRecords[i]
-	})
+// TimeLimitOr returns the time limit of the question, or the given default if the question has none
+func (question *Question) TimeLimitOr(defaultLimit time.Duration) time.Duration {
+	if question.TimeLimit > 0 {
+		return question.TimeLimit
+	}
+	return defaultLimit
 }
 
-func startQuiz(quizRecords []QuizRecord, timelimit time.Duration) <-chan *QuizRecord {
-	var (
-		answerCh = make(chan string)
-		respCh   = make(chan *QuizRecord)
-		timer    = time.NewTimer(timelimit)
-		response string
-	)
-
-	go func() {
-		defer close(answerCh)
-
-		for lineNum := range quizRecords {
-			record := &quizRecords[lineNum]
-			go func() {
-				if _, err := fmt.Scanln(&response); err == nil {
-					answerCh <- strings.TrimSpace(response)
-				}
-			}()
-			select {
-			case <-timer.C:
-				fmt.Printf("\nTimeout!\n")
-				respCh <- nil
-				return
-			case record.response = <-answerCh:
-				respCh <- record
-			}
-		}
-	}()
+// NewRand creates the random number generator of a session. A seed of 0 picks a
+// new seed from the clock. The seed is returned so that the session can be replayed
+func NewRand(seed int64) (*rand.Rand, int64) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed)), seed
+}
 
-	return respCh
+// Shuffle puts the records, and the options of each multiple choice question, in
+// a random order. The same rng state always gives the same order
+func Shuffle(records []Record, rng *rand.Rand) {
+	rng.Shuffle(len(records), func(i, j int) {
+		records[i], records[j] = records[j], records[i]
+	})
+	for idx := range records {
+		options := records[idx].Options
+		rng.Shuffle(len(options), func(i, j int) {
+			options[i], options[j] = options[j], options[i]
+		})
+	}
 }
@@ -0,0 +1,46 @@
+package quiz
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQuiz_Records(t *testing.T) {
+	source, _ := NewSource(formatCSV, strings.NewReader("question,options,answer\n1+1,1|2|3,2\n"))
+	q, err := FromSource(source)
+	if err != nil {
+		t.Fatalf("FromSource() received an error: %v", err)
+	}
+
+	records := q.Records()
+	records[0].Options[0] = "changed"
+	records[0].Response = "2"
+	if q.Questions[0].Options[0] != "1" {
+		t.Errorf("Records(): the options of the quiz should not be shared with its records")
+	}
+	if fresh := q.Records(); fresh[0].Response != "" {
+		t.Errorf("Records(): want fresh records without responses, got %q", fresh[0].Response)
+	}
+}
+
+func TestShuffle_seed(t *testing.T) {
+	source, _ := NewSource(formatCSV, strings.NewReader("question,options,answer\n1+1,1|2|3,2\n2+2,3|4|5,4\n3+3,5|6|7,6\n4+4,7|8|9,8\n"))
+	q, _ := FromSource(source)
+
+	order := func(seed int64) string {
+		rng, _ := NewRand(seed)
+		records := q.Sample(3, rng).Records()
+		Shuffle(records, rng)
+		var sb strings.Builder
+		for _, record := range records {
+			sb.WriteString(record.Text + strings.Join(record.Options, "") + ";")
+		}
+		return sb.String()
+	}
+	if first, second := order(42), order(42); first != second {
+		t.Errorf("Shuffle(): want the same order for the same seed, got %s and %s", first, second)
+	}
+	if _, seed := NewRand(0); seed == 0 {
+		t.Errorf("NewRand(0): want a new seed to be picked")
+	}
+}
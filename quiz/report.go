@@ -0,0 +1,371 @@
+package quiz
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	formatMarkdown = "md"
+
+	statusAnswered   = "answered"
+	statusTimedOut   = "timed out"
+	statusNotReached = "not reached"
+)
+
+// Report is the outcome of a quiz session with a breakdown of every question.
+// The raw score counts the correct answers, while the weighted score adds up the
+// points of every question along with any partial credit and penalties. The seed
+// of the session replays the same questions in the same order
+type Report struct {
+	Correct    int             `json:"correct"`
+	Total      int             `json:"total"`
+	Points     float64         `json:"points"`
+	MaxPoints  float64         `json:"max_points"`
+	Penalty    float64         `json:"penalty,omitempty"`
+	Skill      float64         `json:"skill,omitempty"`
+	Seed       int64           `json:"seed,omitempty"`
+	Categories []CategoryScore `json:"categories"`
+	Questions  []ReportEntry   `json:"questions"`
+}
+
+// CategoryScore is the score of the questions of a single category
+type CategoryScore struct {
+	Category  string  `json:"category"`
+	Correct   int     `json:"correct"`
+	Total     int     `json:"total"`
+	Points    float64 `json:"points"`
+	MaxPoints float64 `json:"max_points"`
+}
+
+type ReportEntry struct {
+	Number     int     `json:"number"`
+	Category   string  `json:"category"`
+	Difficulty string  `json:"difficulty,omitempty"`
+	Question   string  `json:"question"`
+	Expected   string  `json:"expected"`
+	Response   string  `json:"response"`
+	Correct    bool    `json:"correct"`
+	Credit     float64 `json:"credit"`
+	Points     float64 `json:"points"`
+	MaxPoints  float64 `json:"max_points"`
+	Status     string  `json:"status"`
+	Seconds    float64 `json:"seconds"`
+	Skips      int     `json:"skips,omitempty"`
+	Hinted     bool    `json:"hinted,omitempty"`
+	// Tests and Passed are the number of tests of a code question, and how many of them passed
+	Tests  int `json:"tests,omitempty"`
+	Passed int `json:"passed,omitempty"`
+}
+
+// NewReport scores the records with the default matcher. Wrong answers lose the
+// penalty as a share of the points of their question
+func NewReport(records []Record, matcher Matcher, penalty float64) *Report {
+	report := Report{Total: len(records), Penalty: penalty}
+	categories := make(map[string]int)
+	hasDifficulty := false
+	for idx := range records {
+		record := &records[idx]
+		entry := ReportEntry{
+			Number:     idx + 1,
+			Category:   record.CategoryName(),
+			Difficulty: record.Difficulty.String(),
+			Question:   record.Summary(),
+			Expected:   record.Answer,
+			Response:   record.Response,
+			Points:     record.Score(matcher, penalty),
+			MaxPoints:  record.Weight(),
+			Status:     record.status(),
+			Seconds:    record.Elapsed.Seconds(),
+			Skips:      record.Skips,
+			Hinted:     record.Hinted,
+		}
+		if record.IsCode() {
+			entry.Expected = fmt.Sprintf("%d tests", len(record.Code.Names))
+			entry.Tests, entry.Passed = len(record.Code.Names), record.Passed
+		}
+		if record.Answered {
+			entry.Credit = record.Credit(matcher)
+			entry.Correct = entry.Credit == 1
+		}
+		if entry.Correct {
+			report.Correct++
+		}
+		report.Points += entry.Points
+		report.MaxPoints += entry.MaxPoints
+		report.Questions = append(report.Questions, entry)
+
+		catIdx, found := categories[entry.Category]
+		if !found {
+			catIdx = len(report.Categories)
+			categories[entry.Category] = catIdx
+			report.Categories = append(report.Categories, CategoryScore{Category: entry.Category})
+		}
+		category := &report.Categories[catIdx]
+		if entry.Correct {
+			category.Correct++
+		}
+		category.Total++
+		category.Points += entry.Points
+		category.MaxPoints += entry.MaxPoints
+		if entry.Difficulty != "" {
+			hasDifficulty = true
+		}
+	}
+	if hasDifficulty {
+		report.Skill = EstimateSkill(records, matcher)
+	}
+	return &report
+}
+
+func (record *Record) status() string {
+	switch {
+	case record.Answered:
+		return statusAnswered
+	case record.Asked:
+		return statusTimedOut
+	}
+	return statusNotReached
+}
+
+func (report *Report) Percent() float64 {
+	if report.Total == 0 {
+		return 0
+	}
+	return float64(report.Correct) / float64(report.Total) * 100
+}
+
+// WeightedPercent is the weighted score as a percentage of the points of all
+// questions. It may be negative when wrong answers are penalised
+func (report *Report) WeightedPercent() float64 {
+	if report.MaxPoints == 0 {
+		return 0
+	}
+	return report.Points / report.MaxPoints * 100
+}
+
+// Score is the weighted score, eg. 7.5/10
+func (report *Report) Score() string {
+	return formatPoints(report.Points) + "/" + formatPoints(report.MaxPoints)
+}
+
+// SkillLevel describes the estimated skill, which is only known when the questions have a difficulty
+func (report *Report) SkillLevel() string {
+	if report.Skill == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s (%.1f of %d)", SkillLevel(report.Skill), report.Skill, DifficultyHard)
+}
+
+// IsCategorized checks if any of the questions has a category, which is when the
+// breakdown by category is worth showing
+func (report *Report) IsCategorized() bool {
+	return len(report.Categories) > 1 || len(report.Categories) == 1 && report.Categories[0].Category != uncategorized
+}
+
+func (score *CategoryScore) Percent() float64 {
+	if score.Total == 0 {
+		return 0
+	}
+	return float64(score.Correct) / float64(score.Total) * 100
+}
+
+func (score *CategoryScore) Score() string {
+	return formatPoints(score.Points) + "/" + formatPoints(score.MaxPoints)
+}
+
+func (entry *ReportEntry) Result() string {
+	switch {
+	case entry.Status != statusAnswered:
+		return entry.Status
+	case entry.Correct:
+		return "correct"
+	case entry.Credit > 0:
+		return "partial"
+	}
+	return "wrong"
+}
+
+// Reached checks if the question was asked, rather than left out when the
+// time of the quiz ran out or the quiz was stopped
+func (entry *ReportEntry) Reached() bool {
+	return entry.Status != statusNotReached
+}
+
+// Notes tells if the question was skipped, its hint was revealed or how many
+// tests passed, eg. 'skipped twice, hint' or 'passed 2/3 tests'
+func (entry *ReportEntry) Notes() string {
+	var notes []string
+	if entry.Tests > 0 && entry.Status == statusAnswered {
+		notes = append(notes, fmt.Sprintf("passed %d/%d tests", entry.Passed, entry.Tests))
+	}
+	switch {
+	case entry.Skips == 1:
+		notes = append(notes, "skipped")
+	case entry.Skips == 2:
+		notes = append(notes, "skipped twice")
+	case entry.Skips > 2:
+		notes = append(notes, fmt.Sprintf("skipped %d times", entry.Skips))
+	}
+	if entry.Hinted {
+		notes = append(notes, "hint")
+	}
+	return strings.Join(notes, ", ")
+}
+
+// HasNotes checks if any question has notes, see ReportEntry.Notes
+func (report *Report) HasNotes() bool {
+	for idx := range report.Questions {
+		if report.Questions[idx].Notes() != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ShortResponse is the first line of the response, eg. of the code written for a code question
+func (entry *ReportEntry) ShortResponse() string {
+	return firstLine(entry.Response)
+}
+
+func (entry *ReportEntry) Score() string {
+	return formatPoints(entry.Points) + "/" + formatPoints(entry.MaxPoints)
+}
+
+func (entry *ReportEntry) Elapsed() string {
+	if !entry.Reached() {
+		return "-"
+	}
+	return (time.Duration(entry.Seconds * float64(time.Second))).Round(100 * time.Millisecond).String()
+}
+
+// Print writes the breakdown of the report as a table
+func (report *Report) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	hasNotes := report.HasNotes()
+	fmt.Fprint(tw, "#\tQuestion\tExpected\tResponse\tResult\tPoints\tTime")
+	if hasNotes {
+		fmt.Fprint(tw, "\tNotes")
+	}
+	fmt.Fprintln(tw)
+	for _, entry := range report.Questions {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s", entry.Number, entry.Question, entry.Expected, entry.ShortResponse(),
+			entry.Result(), entry.Score(), entry.Elapsed())
+		if hasNotes {
+			fmt.Fprintf(tw, "\t%s", entry.Notes())
+		}
+		fmt.Fprintln(tw)
+	}
+
+	if report.IsCategorized() {
+		fmt.Fprintln(tw, "\nCategory\tScore\tPercent\tPoints")
+		for _, score := range report.Categories {
+			fmt.Fprintf(tw, "%s\t%d/%d\t%.1f%%\t%s\n", score.Category, score.Correct, score.Total, score.Percent(), score.Score())
+		}
+	}
+	return tw.Flush()
+}
+
+// ReportFormat returns the format the report should be exported as. An explicit
+// format always wins over the one implied by the file extension
+func ReportFormat(path, format string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	switch format = strings.ToLower(format); format {
+	case formatJSON, formatCSV:
+		return format, nil
+	case formatMarkdown, "markdown":
+		return formatMarkdown, nil
+	}
+	return "", fmt.Errorf("unsupported report format %q", format)
+}
+
+func (report *Report) Export(w io.Writer, format string) error {
+	switch format {
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case formatCSV:
+		return report.exportCSV(w)
+	case formatMarkdown:
+		return report.exportMarkdown(w)
+	}
+	return fmt.Errorf("unsupported report format %q", format)
+}
+
+func (report *Report) exportCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	//goland:noinspection GoUnhandledErrorResult
+	writer.Write([]string{"number", "category", "question", "expected", "response", "correct", "credit", "points", "max_points", "status", "seconds", "skips", "hinted"})
+	for _, entry := range report.Questions {
+		//goland:noinspection GoUnhandledErrorResult
+		writer.Write([]string{
+			strconv.Itoa(entry.Number),
+			entry.Category,
+			entry.Question,
+			entry.Expected,
+			entry.Response,
+			strconv.FormatBool(entry.Correct),
+			strconv.FormatFloat(entry.Credit, 'f', -1, 64),
+			strconv.FormatFloat(entry.Points, 'f', -1, 64),
+			strconv.FormatFloat(entry.MaxPoints, 'f', -1, 64),
+			entry.Status,
+			strconv.FormatFloat(entry.Seconds, 'f', 3, 64),
+			strconv.Itoa(entry.Skips),
+			strconv.FormatBool(entry.Hinted),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (report *Report) exportMarkdown(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Quiz report\n\nScore: **%d/%d** (%.1f%%)\n\n", report.Correct, report.Total, report.Percent())
+	fmt.Fprintf(&sb, "Weighted score: **%s** (%.1f%%)\n\n", report.Score(), report.WeightedPercent())
+	if skill := report.SkillLevel(); skill != "" {
+		fmt.Fprintf(&sb, "Estimated skill level: **%s**\n\n", skill)
+	}
+	if report.Seed != 0 {
+		fmt.Fprintf(&sb, "Seed: `%d`\n\n", report.Seed)
+	}
+	hasNotes := report.HasNotes()
+	if hasNotes {
+		sb.WriteString("| # | Question | Expected | Response | Result | Points | Time | Notes |\n")
+		sb.WriteString("|---|----------|----------|----------|--------|--------|------|-------|\n")
+	} else {
+		sb.WriteString("| # | Question | Expected | Response | Result | Points | Time |\n")
+		sb.WriteString("|---|----------|----------|----------|--------|--------|------|\n")
+	}
+	for _, entry := range report.Questions {
+		fmt.Fprintf(&sb, "| %d | %s | %s | %s | %s | %s | %s |", entry.Number, markdownCell(entry.Question),
+			markdownCell(entry.Expected), markdownCell(entry.Response), entry.Result(), entry.Score(), entry.Elapsed())
+		if hasNotes {
+			fmt.Fprintf(&sb, " %s |", entry.Notes())
+		}
+		sb.WriteString("\n")
+	}
+	if report.IsCategorized() {
+		sb.WriteString("\n## Categories\n\n| Category | Score | Percent | Points |\n|----------|-------|---------|--------|\n")
+		for _, score := range report.Categories {
+			fmt.Fprintf(&sb, "| %s | %d/%d | %.1f%% | %s |\n", markdownCell(score.Category), score.Correct, score.Total,
+				score.Percent(), score.Score())
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
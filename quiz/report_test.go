@@ -0,0 +1,153 @@
+package quiz
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestReportEntry_Reached(t *testing.T) {
+	records := []Record{
+		{Question: Question{Text: "1+1", Answer: "2"}, Asked: true, Answered: true, Response: "3"},
+		{Question: Question{Text: "2+2", Answer: "4"}, Asked: true},
+		{Question: Question{Text: "3+3", Answer: "6"}},
+	}
+	report := NewReport(records, &textMatcher{}, 0)
+	for idx, want := range []bool{true, true, false} {
+		if got := report.Questions[idx].Reached(); got != want {
+			t.Errorf("Reached() of %q: want %t, got %t", records[idx].Text, want, got)
+		}
+	}
+}
+
+// newExportReport is a report with a correct answer whose cells need escaping
+// in markdown, a wrong answer which was skipped and hinted, and a question
+// which was not reached
+func newExportReport() *Report {
+	records := []Record{
+		{Question: Question{Text: "Either | or", Answer: "a|b", Category: "logic"}, Asked: true, Answered: true, Response: "a|b"},
+		{Question: Question{Text: "2+2", Answer: "4", Category: "math"}, Asked: true, Answered: true, Response: "5\nor 6", Skips: 1, Hinted: true},
+		{Question: Question{Text: "3+3", Answer: "6", Category: "math"}},
+	}
+	report := NewReport(records, &textMatcher{}, 0)
+	report.Seed = 42
+	return report
+}
+
+func TestReport_Export_json(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newExportReport().Export(&buf, formatJSON); err != nil {
+		t.Fatalf("Export() received an error: %v", err)
+	}
+	var got struct {
+		Correct    int                      `json:"correct"`
+		Total      int                      `json:"total"`
+		Points     float64                  `json:"points"`
+		MaxPoints  float64                  `json:"max_points"`
+		Seed       int64                    `json:"seed"`
+		Categories []map[string]interface{} `json:"categories"`
+		Questions  []map[string]interface{} `json:"questions"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Export(): want valid json, got %v", err)
+	}
+	if got.Correct != 1 || got.Total != 3 || got.Points != 1 || got.MaxPoints != 3 || got.Seed != 42 || len(got.Categories) != 2 {
+		t.Errorf("Export(): want the scores of the report, got %+v", got)
+	}
+	if len(got.Questions) != 3 {
+		t.Fatalf("Export(): want 3 questions, got %d", len(got.Questions))
+	}
+	wrong := got.Questions[1]
+	for key, want := range map[string]interface{}{"number": 2.0, "response": "5\nor 6", "correct": false, "status": statusAnswered, "skips": 1.0, "hinted": true} {
+		if wrong[key] != want {
+			t.Errorf("Export(): want %s to be %v, got %v", key, want, wrong[key])
+		}
+	}
+	if _, found := got.Questions[0]["skips"]; found {
+		t.Errorf("Export(): want the skips to be left out when there are none, got %v", got.Questions[0])
+	}
+	if status := got.Questions[2]["status"]; status != statusNotReached {
+		t.Errorf("Export(): want the last question not reached, got %v", status)
+	}
+}
+
+func TestReport_Export_csv(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newExportReport().Export(&buf, formatCSV); err != nil {
+		t.Fatalf("Export() received an error: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Export(): want valid csv, got %v", err)
+	}
+	want := [][]string{
+		{"number", "category", "question", "expected", "response", "correct", "credit", "points", "max_points", "status", "seconds", "skips", "hinted"},
+		{"1", "logic", "Either | or", "a|b", "a|b", "true", "1", "1", "1", statusAnswered, "0.000", "0", "false"},
+		{"2", "math", "2+2", "4", "5\nor 6", "false", "0", "0", "1", statusAnswered, "0.000", "1", "true"},
+		{"3", "math", "3+3", "6", "", "false", "0", "0", "1", statusNotReached, "0.000", "0", "false"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Export(): want %d rows, got %d", len(want), len(rows))
+	}
+	for idx := range want {
+		if strings.Join(rows[idx], ",") != strings.Join(want[idx], ",") {
+			t.Errorf("Export(): want row %d to be %q, got %q", idx, want[idx], rows[idx])
+		}
+	}
+}
+
+func TestReport_Export_markdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newExportReport().Export(&buf, formatMarkdown); err != nil {
+		t.Fatalf("Export() received an error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Score: **1/3** (33.3%)",
+		"Seed: `42`",
+		"| 1 | Either \\| or | a\\|b | a\\|b | correct | 1/1 |",
+		"| 2 | 2+2 | 4 | 5<br>or 6 | wrong | 0/1 |",
+		"| 3 | 3+3 | 6 |  | not reached | 0/1 | - |  |",
+		"skipped, hint |",
+		"## Categories",
+		"| math | 0/2 | 0.0% | 0/2 |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Export(): want %q in the markdown, got:\n%s", want, out)
+		}
+	}
+
+	//every row of the table has as many cells as its header, once the escaped pipes are left out
+	lines := strings.Split(out, "\n")
+	var columns int
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "| # |") && columns == 0 {
+			continue
+		}
+		if line == "" {
+			break
+		}
+		cells := strings.Count(strings.ReplaceAll(line, `\|`, ""), "|")
+		if columns == 0 {
+			columns = cells
+		} else if cells != columns {
+			t.Errorf("Export(): want %d cell separators in %q, got %d", columns, line, cells)
+		}
+	}
+	if columns != 9 {
+		t.Errorf("Export(): want a table with a notes column, got %d cell separators", columns)
+	}
+}
+
+func TestReport_Export_unsupported(t *testing.T) {
+	if err := newExportReport().Export(&bytes.Buffer{}, "xml"); err == nil {
+		t.Errorf("Export(): expected an error for an unsupported format")
+	}
+	for path, want := range map[string]string{"report.md": formatMarkdown, "report.JSON": formatJSON, "report.csv": formatCSV} {
+		if got, err := ReportFormat(path, ""); err != nil || got != want {
+			t.Errorf("ReportFormat(%q): want %s, got %s (%v)", path, want, got, err)
+		}
+	}
+}
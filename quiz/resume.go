@@ -0,0 +1,104 @@
+package quiz
+
+import (
+	"fmt"
+	"time"
+)
+
+// SessionState is what is kept of a paused session to resume it later. The
+// questions themselves are not kept, they are found again by their text in the quiz
+type SessionState struct {
+	// TimeLeft is the time that was left for the whole quiz, 0 if it has no time limit
+	TimeLeft time.Duration `json:"time_left"`
+	Records  []RecordState `json:"records"`
+}
+
+// RecordState is the saved state of a single record, in the order the records are
+// asked. The options are kept in the order they were shown, which may have been shuffled
+type RecordState struct {
+	Question string        `json:"question"`
+	Options  []string      `json:"options,omitempty"`
+	Response string        `json:"response,omitempty"`
+	Asked    bool          `json:"asked"`
+	Answered bool          `json:"answered"`
+	Elapsed  time.Duration `json:"elapsed"`
+	Skips    int           `json:"skips,omitempty"`
+	Hinted   bool          `json:"hinted,omitempty"`
+	HintCost float64       `json:"hint_cost,omitempty"`
+	Passed   int           `json:"passed,omitempty"`
+}
+
+// State returns the state of a session, which is meant to be saved once the session was paused
+func (s *Session) State() *SessionState {
+	state := &SessionState{TimeLeft: s.TimeLimit}
+	for idx := range s.Records {
+		record := &s.Records[idx]
+		state.Records = append(state.Records, RecordState{
+			Question: record.Text,
+			Options:  record.Options,
+			Response: record.Response,
+			Asked:    record.Asked,
+			Answered: record.Answered,
+			Elapsed:  record.Elapsed,
+			Skips:    record.Skips,
+			Hinted:   record.Hinted,
+			HintCost: record.HintCost,
+			Passed:   record.Passed,
+		})
+	}
+	return state
+}
+
+// Restore rebuilds the records of the saved session from the questions of its quiz.
+// It fails if a question can no longer be found, eg. because the quiz file was changed
+func (state *SessionState) Restore(questions []Question) ([]Record, error) {
+	byText := make(map[string][]int)
+	for idx := range questions {
+		byText[questions[idx].Text] = append(byText[questions[idx].Text], idx)
+	}
+
+	records := make([]Record, 0, len(state.Records))
+	for _, saved := range state.Records {
+		//a question which is asked more than once is matched in the order it appears
+		indexes := byText[saved.Question]
+		if len(indexes) == 0 {
+			return nil, fmt.Errorf("the question %q is no longer in the quiz", saved.Question)
+		}
+		byText[saved.Question] = indexes[1:]
+
+		question := questions[indexes[0]]
+		if !sameOptions(question.Options, saved.Options) {
+			return nil, fmt.Errorf("the options of the question %q have changed", saved.Question)
+		}
+		question.Options = append([]string(nil), saved.Options...)
+		records = append(records, Record{
+			Question: question,
+			Response: saved.Response,
+			Asked:    saved.Asked,
+			Answered: saved.Answered,
+			Elapsed:  saved.Elapsed,
+			Skips:    saved.Skips,
+			Hinted:   saved.Hinted,
+			HintCost: saved.HintCost,
+			Passed:   saved.Passed,
+		})
+	}
+	return records, nil
+}
+
+// sameOptions checks if both lists have the same options, in any order
+func sameOptions(options, saved []string) bool {
+	if len(options) != len(saved) {
+		return false
+	}
+	counts := make(map[string]int)
+	for _, option := range options {
+		counts[option]++
+	}
+	for _, option := range saved {
+		if counts[option]--; counts[option] < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,27 @@
+//go:build unix
+
+package quiz
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// sandboxCommand runs the binary with limits on its memory and cpu time. It gets
+// its own process group, so that the processes it starts are killed along with it
+func sandboxCommand(binary string, args []string, memory int64, timeout time.Duration) *exec.Cmd {
+	//the data segment is limited rather than the address space, which the Go
+	//runtime reserves far more of than it uses
+	cpu := int64(timeout/time.Second) + 1
+	script := fmt.Sprintf(`ulimit -d %d && ulimit -t %d && exec "$0" "$@"`, memory>>10, cpu)
+	cmd := exec.Command("/bin/sh", append([]string{"-c", script, binary}, args...)...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	return cmd
+}
+
+func killSandbox(cmd *exec.Cmd) {
+	//goland:noinspection GoUnhandledErrorResult
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
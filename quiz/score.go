@@ -0,0 +1,84 @@
+package quiz
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPoints = 1
+	// partSeparator separates the parts of the response to a multi-part question
+	partSeparator = ","
+)
+
+// Weight is the number of points the question is worth
+func (question *Question) Weight() float64 {
+	if question.Points > 0 {
+		return question.Points
+	}
+	return defaultPoints
+}
+
+func (question *Question) IsMultiPart() bool {
+	return len(question.Parts) > 0
+}
+
+// Credit is the share of the points earned by the response, from 0 to 1. Only
+// multi-part and code questions earn partial credit, with an equal share for each
+// part which was answered or test which passed. The parts may be given in any order
+func (record *Record) Credit(defaultMatcher Matcher) float64 {
+	if record.IsCode() {
+		return float64(record.Passed) / float64(len(record.Code.Names))
+	}
+	if !record.IsMultiPart() {
+		if record.IsCorrect(defaultMatcher) {
+			return 1
+		}
+		return 0
+	}
+
+	matcher := record.matcherOr(defaultMatcher)
+	matched := make([]bool, len(record.Parts))
+	responses := strings.Split(record.Response, partSeparator)
+	if len(responses) > len(record.Parts) {
+		//listing more parts than were asked for must not help to guess the right ones
+		responses = responses[:len(record.Parts)]
+	}
+
+	var correct int
+	for _, response := range responses {
+		response = strings.TrimSpace(response)
+		for idx, part := range record.Parts {
+			if !matched[idx] && matcher.Match(part, response) {
+				matched[idx] = true
+				correct++
+				break
+			}
+		}
+	}
+	return float64(correct) / float64(len(record.Parts))
+}
+
+// Score is the number of points earned by the response. A wrong answer loses
+// the penalty, as a share of the points of the question, while a question which
+// was not answered scores nothing. A revealed hint takes its cost off the share
+// of the points which was earned, down to nothing
+func (record *Record) Score(defaultMatcher Matcher, penalty float64) float64 {
+	if !record.Answered {
+		return 0
+	}
+	credit := record.Credit(defaultMatcher)
+	if credit == 0 && penalty > 0 {
+		return -penalty * record.Weight()
+	}
+	if record.Hinted {
+		credit = math.Max(0, credit-record.HintCost)
+	}
+	return credit * record.Weight()
+}
+
+// formatPoints shows points with at most two decimals, eg. 1, 0.5 or 0.67
+func formatPoints(points float64) string {
+	return strconv.FormatFloat(math.Round(points*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,74 @@
+package quiz
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecord_Credit(t *testing.T) {
+	nocase, _ := ParseMatcher(matchNoCase)
+	record := Record{Question: Question{Text: "Primary colours?", Answer: "red, green, blue", Parts: []string{"red", "green", "blue"}}}
+
+	tests := []struct {
+		response string
+		want     float64
+	}{
+		{"red, green, blue", 1},
+		{"Blue,RED , green", 1},
+		{"red, yellow, blue", 2.0 / 3},
+		{"red, red, red", 1.0 / 3},
+		{"yellow, pink, red, green, blue", 1.0 / 3},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		record.Response = tt.response
+		if got := record.Credit(nocase); got != tt.want {
+			t.Errorf("Credit() with response %q: want %.2f, got %.2f", tt.response, tt.want, got)
+		}
+	}
+}
+
+func TestNewReport_weighted(t *testing.T) {
+	exact, _ := ParseMatcher(matchExact)
+	records := []Record{
+		{Question: Question{Text: "5+5", Answer: "10", Points: 2}, Response: "10", Answered: true, Asked: true},
+		{Question: Question{Text: "1+1", Answer: "2"}, Response: "3", Answered: true, Asked: true},
+		{Question: Question{Text: "a, b?", Answer: "a, b", Parts: []string{"a", "b"}, Points: 4}, Response: "b, c", Answered: true, Asked: true},
+		{Question: Question{Text: "8+3", Answer: "11", Points: 3}, Asked: true},
+	}
+
+	report := NewReport(records, exact, 0.5)
+	if report.Correct != 1 || report.Total != 4 {
+		t.Errorf("raw score: want 1/4, got %d/%d", report.Correct, report.Total)
+	}
+	//2 for the first question, -0.5 for the wrong answer, half of 4 for the partial answer and nothing for the unanswered one
+	if got := report.Score(); got != "3.5/10" {
+		t.Errorf("weighted score: want %s, got %s", "3.5/10", got)
+	}
+	results := make([]string, len(report.Questions))
+	for idx := range report.Questions {
+		results[idx] = report.Questions[idx].Result()
+	}
+	if got := strings.Join(results, ","); got != "correct,wrong,partial,timed out" {
+		t.Errorf("results: want %s, got %s", "correct,wrong,partial,timed out", got)
+	}
+}
+
+func TestRecord_Score_hint(t *testing.T) {
+	exact, _ := ParseMatcher(matchExact)
+	tests := []struct {
+		record Record
+		want   float64
+	}{
+		{Record{Question: Question{Answer: "10", Points: 2}, Response: "10", Answered: true, Hinted: true, HintCost: 0.25}, 1.5},
+		{Record{Question: Question{Answer: "a, b", Parts: []string{"a", "b"}}, Response: "a", Answered: true, Hinted: true, HintCost: 0.75}, 0},
+		{Record{Question: Question{Answer: "10"}, Response: "9", Answered: true, Hinted: true, HintCost: 0.5}, -0.5},
+		{Record{Question: Question{Answer: "10"}, Response: "10", Answered: true, Hinted: true}, 1},
+	}
+
+	for _, tt := range tests {
+		if got := tt.record.Score(exact, 0.5); got != tt.want {
+			t.Errorf("Score(%q, hint cost %v): want %v, got %v", tt.record.Response, tt.record.HintCost, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package quiz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// The commands which may be given instead of a response
+const (
+	// PauseCommand pauses the session
+	PauseCommand = ":pause"
+	// SkipCommand leaves the question to be asked again after all the others
+	SkipCommand = ":skip"
+	// HintCommand reveals the hint of the question, at the HintCost of the session
+	HintCommand = ":hint"
+	// BackCommand goes back to the previous question to change its response
+	BackCommand = ":back"
+	// EndCommand ends the code written as the response to a code question
+	EndCommand = ":end"
+)
+
+var (
+	errPaused  = errors.New("the session was paused")
+	errSkipped = errors.New("the question was skipped")
+	errBack    = errors.New("went back to the previous question")
+)
+
+// Session asks the questions of a quiz and collects the responses
+type Session struct {
+	Records []Record
+	// TimeLimit is the time limit of the whole quiz, if any
+	TimeLimit time.Duration
+	// PerQuestion is the default time limit of each question
+	PerQuestion time.Duration
+	Input       *InputReader
+	Output      io.Writer
+	// Order picks the question to ask next. Without an Order, the records are asked in turn
+	Order Order
+	// Pause pauses the session, just like the PauseCommand
+	Pause <-chan struct{}
+	// HintCost is the share of the points of a question which is lost by revealing its hint
+	HintCost float64
+	// Grader runs the tests of code questions, with its default limits if it is not set
+	Grader *Grader
+	// Color uses colors in the prompts, eg. to highlight the code of Markdown questions
+	Color bool
+	// Log records the questions, each line which is submitted, the timeouts and
+	// the pauses of a proctored session
+	Log *EventLog
+
+	paused bool
+}
+
+// Order chooses which of the remaining records is asked next, given the record
+// which was asked last, if any. It returns the index of the chosen record
+type Order interface {
+	Next(remaining []Record, last *Record) int
+}
+
+// Prompter is an Output which shows the questions itself, eg. a full screen
+// display. It is given each question instead of its prompt, along with the time
+// the question runs out if it has a time limit
+type Prompter interface {
+	io.Writer
+	Prompt(number int, question Question, deadline time.Time)
+}
+
+// Start asks each question in turn and sends it once it has been answered or
+// has timed out. The channel is closed when the quiz is over, which is either when
+// all questions were asked, the time limit was reached, the input ran out or ctx was cancelled.
+// A quiz without a time limit only ends for the other reasons.
+//
+// The quiz also ends when it is paused, in which case the question being asked is
+// left for later and the time limit is set to the time that was left. Starting
+// the session again resumes it from the first question which was not asked
+func (s *Session) Start(ctx context.Context) <-chan *Record {
+	respCh := make(chan *Record)
+
+	go func() {
+		defer close(respCh)
+		//the last response is still graded once the time is up
+		parent := ctx
+		var cancel context.CancelFunc
+		var deadline time.Time
+		if s.TimeLimit > 0 {
+			deadline = time.Now().Add(s.TimeLimit)
+			ctx, cancel = context.WithDeadline(ctx, deadline)
+		} else {
+			ctx, cancel = context.WithCancel(ctx)
+		}
+		defer cancel()
+
+		s.paused = false
+		for position := s.next(); position < len(s.Records); {
+			//a question which is asked again after going back was already picked
+			if !s.Records[position].Asked {
+				s.pick(position)
+			}
+			record := &s.Records[position]
+			err := s.ask(ctx, position, record)
+			switch {
+			case err == nil:
+				if record.Answered && record.IsCode() {
+					s.grade(parent, record)
+				}
+				respCh <- record
+				position++
+				continue
+			case errors.Is(err, errSkipped):
+				s.skip(position)
+				continue
+			case errors.Is(err, errBack):
+				position--
+				if previous := &s.Records[position]; previous.Answered {
+					fmt.Fprintf(s.Output, "\nYour response was: %s\n", previous.Response)
+				}
+				continue
+			case errors.Is(err, errPaused):
+				s.paused = true
+				s.Log.Add(EventPause, position+1, "")
+				if s.TimeLimit > 0 {
+					//a session which is paused at its very deadline still has to end on resume
+					if s.TimeLimit = time.Until(deadline); s.TimeLimit <= 0 {
+						s.TimeLimit = time.Nanosecond
+					}
+				}
+			case errors.Is(err, context.DeadlineExceeded):
+				s.Log.Add(EventTimeout, 0, "")
+				fmt.Fprintf(s.Output, "\nTimeout!\n")
+			}
+			return
+		}
+	}()
+
+	return respCh
+}
+
+// Paused checks if the session ended because it was paused
+func (s *Session) Paused() bool {
+	return s.paused
+}
+
+// next is the position of the first record which was not asked yet. The records
+// before it were asked before the session was paused
+func (s *Session) next() int {
+	for idx := range s.Records {
+		if !s.Records[idx].Asked {
+			return idx
+		}
+	}
+	return len(s.Records)
+}
+
+// pick moves the record chosen by the Order to the given position, so that the
+// records end up in the order in which they were asked. The records which were
+// not asked yet keep their order
+func (s *Session) pick(position int) {
+	if s.Order == nil {
+		return
+	}
+	var last *Record
+	if position > 0 {
+		last = &s.Records[position-1]
+	}
+	if idx := position + s.Order.Next(s.Records[position:], last); idx != position {
+		chosen := s.Records[idx]
+		copy(s.Records[position+1:idx+1], s.Records[position:idx])
+		s.Records[position] = chosen
+	}
+}
+
+// skip moves the record at the given position to the end, so that it is asked
+// again once all the other records were asked
+func (s *Session) skip(position int) {
+	skipped := s.Records[position]
+	copy(s.Records[position:], s.Records[position+1:])
+	s.Records[len(s.Records)-1] = skipped
+}
+
+// ask waits for the response to a single question, handling any command given
+// instead. A question which runs out of time is left unanswered and does not end
+// the quiz. A question which is left by a command keeps the time that was spent
+// on it for when it is asked again
+func (s *Session) ask(ctx context.Context, position int, record *Record) error {
+	questionCtx, cancel := ctx, context.CancelFunc(func() {})
+	var deadline time.Time
+	if limit := record.TimeLimitOr(s.PerQuestion); limit > 0 {
+		deadline = time.Now().Add(limit - record.Elapsed)
+		questionCtx, cancel = context.WithDeadline(ctx, deadline)
+	}
+	defer cancel()
+
+	prompter, isPrompter := s.Output.(Prompter)
+	if isPrompter {
+		prompter.Prompt(position+1, record.Question, deadline)
+	} else {
+		fmt.Fprint(s.Output, record.Prompt(position+1, s.Color))
+	}
+	s.Log.Add(EventQuestion, position+1, record.Summary())
+
+	paused := make(chan struct{})
+	if s.Pause != nil {
+		var unpause context.CancelFunc
+		questionCtx, unpause = context.WithCancel(questionCtx)
+		defer unpause()
+		go func() {
+			select {
+			case <-s.Pause:
+				close(paused)
+				unpause()
+			case <-questionCtx.Done():
+			}
+		}()
+	}
+
+	start := time.Now()
+	record.Asked = true
+	defer func() {
+		record.Elapsed += time.Since(start)
+	}()
+	var code []string
+	for {
+		response, err := s.Input.ReadLine(questionCtx)
+		if err == nil {
+			s.Log.Add(EventSubmit, position+1, response)
+		}
+		switch {
+		case err == nil && record.IsCode() && response == EndCommand:
+			record.Response, record.Answered = strings.Join(code, "\n"), true
+			return nil
+		case err == nil && len(code) > 0, err == nil && record.IsCode() && !isCommand(response):
+			//the commands can only be given before starting to write the code
+			code = append(code, response)
+			continue
+		case err == nil && response == HintCommand:
+			s.hint(record)
+		case err == nil && response == SkipCommand:
+			if s.canSkip(position) {
+				record.Asked = false
+				record.Skips++
+				return errSkipped
+			}
+		case err == nil && response == BackCommand:
+			if s.canGoBack(position) {
+				record.Asked = record.Answered
+				return errBack
+			}
+		case err == nil && response == PauseCommand:
+			record.Asked = record.Answered
+			return errPaused
+		case err == nil:
+			record.Response, record.Answered = response, true
+			return nil
+		case errors.Is(ctx.Err(), context.Canceled):
+			//a question which was interrupted, eg. with Ctrl-C, was not really asked
+			record.Asked = record.Answered
+			return ctx.Err()
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case isClosed(paused):
+			record.Asked = record.Answered
+			return errPaused
+		case questionCtx.Err() != nil:
+			s.Log.Add(EventTimeout, position+1, "")
+			fmt.Fprintf(s.Output, "\nTime's up for this question!\n")
+			s.Input.Discard()
+			return nil
+		default:
+			//the input ended, eg. with Ctrl-D, before the question was answered or ran out of time
+			record.Asked = record.Answered
+			return err
+		}
+		//the question is still open after a command
+		if !isPrompter {
+			fmt.Fprint(s.Output, "> ")
+		}
+	}
+}
+
+// grade runs the tests of a code question and shows how many passed, along with
+// the errors of code which does not build
+func (s *Session) grade(ctx context.Context, record *Record) {
+	grader := s.Grader
+	if grader == nil {
+		grader = &Grader{}
+	}
+	fmt.Fprintf(s.Output, "Running the tests...\n")
+	grade, err := grader.Grade(ctx, record.Code, record.Response)
+	record.Passed = grade.Passed
+	switch {
+	case err != nil:
+		fmt.Fprintf(s.Output, "The tests could not be run: %v\n", err)
+	case grade.Output != "":
+		fmt.Fprintf(s.Output, "The code does not build:\n%s\n", grade.Output)
+	case len(grade.Failed) > 0:
+		fmt.Fprintf(s.Output, "Passed %d/%d tests, failed %s\n", grade.Passed, grade.Total, strings.Join(grade.Failed, ", "))
+	default:
+		fmt.Fprintf(s.Output, "Passed %d/%d tests\n", grade.Passed, grade.Total)
+	}
+}
+
+// hint reveals the hint of the question. Its cost is only counted once
+func (s *Session) hint(record *Record) {
+	if record.Hint == "" {
+		fmt.Fprintf(s.Output, "There is no hint for this question\n")
+		return
+	}
+	if !record.Hinted {
+		record.Hinted, record.HintCost = true, s.HintCost
+	}
+	if record.HintCost > 0 {
+		fmt.Fprintf(s.Output, "Hint (costs %s%% of the points): %s\n", formatPoints(record.HintCost*100), record.Hint)
+	} else {
+		fmt.Fprintf(s.Output, "Hint: %s\n", record.Hint)
+	}
+}
+
+// canSkip checks if the question can be left for later. This is not possible
+// with an Order, which picks the next question by the response to this one
+func (s *Session) canSkip(position int) bool {
+	switch {
+	case s.Order != nil:
+		fmt.Fprintf(s.Output, "Skipping questions is not possible in this quiz\n")
+	case s.Records[position].Answered:
+		fmt.Fprintf(s.Output, "A question which was answered can't be skipped\n")
+	case position == len(s.Records)-1:
+		fmt.Fprintf(s.Output, "There are no other questions left\n")
+	default:
+		return true
+	}
+	return false
+}
+
+// canGoBack checks if the previous question can still be answered. This is not
+// possible with an Order, which has already picked the questions by the previous responses
+func (s *Session) canGoBack(position int) bool {
+	if s.Order != nil {
+		fmt.Fprintf(s.Output, "Going back is not possible in this quiz\n")
+		return false
+	}
+	if position == 0 {
+		fmt.Fprintf(s.Output, "This is the first question\n")
+		return false
+	}
+	previous := &s.Records[position-1]
+	if limit := previous.TimeLimitOr(s.PerQuestion); limit > 0 && previous.Elapsed >= limit {
+		fmt.Fprintf(s.Output, "The time for the previous question is up\n")
+		return false
+	}
+	return true
+}
+
+func isCommand(response string) bool {
+	switch response {
+	case PauseCommand, SkipCommand, HintCommand, BackCommand:
+		return true
+	}
+	return false
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,238 @@
+package quiz
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSession(ctx context.Context, input io.Reader, records []Record) *Session {
+	return &Session{
+		Records:   records,
+		TimeLimit: time.Second,
+		Input:     NewInputReader(ctx, input),
+		Output:    &bytes.Buffer{},
+	}
+}
+
+func TestQuizSession_scripted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	records := []Record{
+		{Question: Question{Text: "5+5", Answer: "10"}},
+		{Question: Question{Text: "1+1", Answer: "2"}},
+		{Question: Question{Text: "8+3", Answer: "11"}},
+	}
+	session := newTestSession(ctx, strings.NewReader("10\n 3 \n11\n"), records)
+
+	var responses []string
+	for record := range session.Start(ctx) {
+		if !record.Answered {
+			t.Errorf("record %q: want answered", record.Text)
+		}
+		responses = append(responses, record.Response)
+	}
+
+	if got := strings.Join(responses, ","); got != "10,3,11" {
+		t.Errorf("responses: want %s, got %s", "10,3,11", got)
+	}
+	if out := session.Output.(*bytes.Buffer).String(); !strings.Contains(out, "Question #3: 8+3") {
+		t.Errorf("output: want all questions to be asked, got %q", out)
+	}
+}
+
+func TestQuizSession_inputExhausted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	records := []Record{{Question: Question{Text: "5+5", Answer: "10"}}, {Question: Question{Text: "1+1", Answer: "2"}}}
+	session := newTestSession(ctx, strings.NewReader("10\n"), records)
+
+	var count int
+	for range session.Start(ctx) {
+		count++
+	}
+	if count != 1 {
+		t.Errorf("answered questions: want %d, got %d", 1, count)
+	}
+	report := NewReport(session.Records, &textMatcher{}, 0)
+	if status := report.Questions[1].Status; status != statusNotReached {
+		t.Errorf("record %q: want the question open at the end of the input to be %q, got %q", records[1].Text, statusNotReached, status)
+	}
+}
+
+func TestQuizSession_timeouts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	records := []Record{
+		{Question: Question{Text: "5+5", Answer: "10", TimeLimit: 20 * time.Millisecond}},
+		{Question: Question{Text: "1+1", Answer: "2"}},
+		{Question: Question{Text: "8+3", Answer: "11"}},
+	}
+	session := newTestSession(ctx, reader, records)
+	session.TimeLimit = 200 * time.Millisecond
+
+	respCh := session.Start(ctx)
+	if record := <-respCh; record.Answered {
+		t.Errorf("record %q: want the question to time out", record.Text)
+	}
+
+	go writer.Write([]byte("2\n"))
+	if record := <-respCh; !record.Answered || record.Response != "2" {
+		t.Errorf("record %q: want response %q to land on the second question, got %q", record.Text, "2", record.Response)
+	}
+
+	if _, open := <-respCh; open {
+		t.Errorf("expected the quiz to end once the time limit was reached")
+	}
+	if out := session.Output.(*bytes.Buffer).String(); !strings.Contains(out, "Timeout!") {
+		t.Errorf("output: want a timeout message, got %q", out)
+	}
+}
+
+func TestQuizSession_pauseAndResume(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	questions := []Question{
+		{Text: "5+5", Answer: "10"},
+		{Text: "1+1", Answer: "2"},
+		{Text: "8+3", Answer: "11"},
+	}
+	session := newTestSession(ctx, strings.NewReader("10\n"+PauseCommand+"\n"), (&Quiz{Questions: questions}).Records())
+	for range session.Start(ctx) {
+	}
+	if !session.Paused() {
+		t.Fatalf("Paused(): want the session to be paused")
+	}
+	if session.TimeLimit <= 0 || session.TimeLimit > time.Second {
+		t.Errorf("TimeLimit: want the time that was left, got %v", session.TimeLimit)
+	}
+
+	state := session.State()
+	records, err := state.Restore(questions)
+	if err != nil {
+		t.Fatalf("Restore() received an error: %v", err)
+	}
+	if !records[0].Answered || records[0].Response != "10" || records[1].Asked {
+		t.Errorf("Restore(): want the first question answered and the second one left for later, got %+v", records[:2])
+	}
+
+	pause := make(chan struct{}, 1)
+	reader, writer := io.Pipe()
+	defer writer.Close()
+	resumed := newTestSession(ctx, reader, records)
+	resumed.TimeLimit, resumed.Pause = state.TimeLeft, pause
+
+	respCh := resumed.Start(ctx)
+	go writer.Write([]byte("2\n"))
+	if record := <-respCh; record.Text != "1+1" || record.Response != "2" {
+		t.Errorf("resume: want the session to resume at %q, got %q", "1+1", record.Text)
+	}
+	pause <- struct{}{}
+	if _, open := <-respCh; open || !resumed.Paused() {
+		t.Errorf("Pause: want the session to be paused")
+	}
+
+	if _, err := state.Restore(questions[1:]); err == nil {
+		t.Errorf("Restore(): want an error for a question which is no longer in the quiz")
+	}
+}
+
+func TestQuizSession_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reader, writer := io.Pipe()
+	defer writer.Close()
+	records := []Record{{Question: Question{Text: "5+5", Answer: "10"}}, {Question: Question{Text: "1+1", Answer: "2"}}}
+	session := newTestSession(ctx, reader, records)
+	respCh := session.Start(ctx)
+	go writer.Write([]byte("10\n"))
+	<-respCh
+	cancel()
+	for range respCh {
+	}
+
+	if !records[0].Asked || records[1].Asked || records[1].Answered {
+		t.Errorf("records: want only the answered question to be asked once the quiz is stopped, got %+v", records)
+	}
+}
+
+type testPrompter struct {
+	bytes.Buffer
+	numbers   []int
+	deadlines []time.Time
+}
+
+func (p *testPrompter) Prompt(number int, _ Question, deadline time.Time) {
+	p.numbers = append(p.numbers, number)
+	p.deadlines = append(p.deadlines, deadline)
+}
+
+func TestQuizSession_prompter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	records := []Record{
+		{Question: Question{Text: "5+5", Answer: "10", TimeLimit: time.Second}},
+		{Question: Question{Text: "1+1", Answer: "2"}},
+	}
+	session := newTestSession(ctx, strings.NewReader("10\n2\n"), records)
+	prompter := &testPrompter{}
+	session.Output = prompter
+	for range session.Start(ctx) {
+	}
+
+	if len(prompter.numbers) != 2 || prompter.numbers[1] != 2 {
+		t.Errorf("Prompt(): want both questions to be prompted in turn, got %v", prompter.numbers)
+	}
+	if prompter.deadlines[0].IsZero() || !prompter.deadlines[1].IsZero() {
+		t.Errorf("Prompt(): want a deadline only for the question with a time limit, got %v", prompter.deadlines)
+	}
+	if out := prompter.String(); strings.Contains(out, "Question #") {
+		t.Errorf("output: want no plain prompts for a Prompter, got %q", out)
+	}
+}
+
+func TestQuizSession_commands(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	records := []Record{
+		{Question: Question{Text: "5+5", Answer: "10", Hint: "more than 9"}},
+		{Question: Question{Text: "1+1", Answer: "2"}},
+		{Question: Question{Text: "8+3", Answer: "11"}},
+	}
+	input := strings.Join([]string{SkipCommand, BackCommand, "3", BackCommand, "2", "11", HintCommand, HintCommand, "10"}, "\n")
+	session := newTestSession(ctx, strings.NewReader(input+"\n"), records)
+	session.HintCost = 0.5
+	for range session.Start(ctx) {
+	}
+
+	var order, responses []string
+	for _, record := range session.Records {
+		order, responses = append(order, record.Text), append(responses, record.Response)
+	}
+	if got := strings.Join(order, ","); got != "1+1,8+3,5+5" {
+		t.Errorf("order: want the skipped question last, got %s", got)
+	}
+	if got := strings.Join(responses, ","); got != "2,11,10" {
+		t.Errorf("responses: want the response changed after going back, got %s", got)
+	}
+	if last := session.Records[2]; last.Skips != 1 || !last.Hinted || last.HintCost != 0.5 {
+		t.Errorf("record %q: want it skipped once and hinted, got %d skips and hinted %v", last.Text, last.Skips, last.Hinted)
+	}
+	out := session.Output.(*bytes.Buffer).String()
+	if !strings.Contains(out, "This is the first question") || strings.Count(out, "more than 9") != 2 {
+		t.Errorf("output: want the hint and a message about going back, got %q", out)
+	}
+}
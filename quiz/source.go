@@ -0,0 +1,632 @@
+package quiz
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"golang.org/x/text/unicode/norm"
+	"gopkg.in/yaml.v3"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	formatCSV  = "csv"
+	formatJSON = "json"
+	formatYAML = "yaml"
+	formatTOML = "toml"
+)
+
+// QuestionSource provides the questions of a quiz
+type QuestionSource interface {
+	Questions() ([]Question, error)
+}
+
+// SourceError reports a problem with a question found at a specific position in the source
+type SourceError struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *SourceError) Error() string {
+	if e.Column > 0 {
+		return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+const (
+	csvColumnQuestion   = "question"
+	csvColumnAnswer     = "answer"
+	csvColumnOptions    = "options"
+	csvColumnMatch      = "match"
+	csvColumnAliases    = "aliases"
+	csvColumnTimeLimit  = "time_limit"
+	csvColumnParts      = "parts"
+	csvColumnPoints     = "points"
+	csvColumnCategory   = "category"
+	csvColumnTags       = "tags"
+	csvColumnDifficulty = "difficulty"
+	csvColumnHint       = "hint"
+	csvColumnMarkdown   = "markdown"
+	csvColumnTests      = "tests"
+	csvListSeparator    = "|"
+	// csvTranslationSeparator separates a column from the language it is translated to, eg. 'question:fr'
+	csvTranslationSeparator = ":"
+)
+
+// questionEntry is the shape of a question in the structured (json, yaml, toml) formats
+type questionEntry struct {
+	Question string   `json:"question" yaml:"question" toml:"question"`
+	Answer   string   `json:"answer" yaml:"answer" toml:"answer"`
+	Options  []string `json:"options,omitempty" yaml:"options,omitempty" toml:"options,omitempty"`
+	Match    string   `json:"match,omitempty" yaml:"match,omitempty" toml:"match,omitempty"`
+	Aliases  []string `json:"aliases,omitempty" yaml:"aliases,omitempty" toml:"aliases,omitempty"`
+	// TimeLimit is either a duration such as '10s' or a number of seconds
+	TimeLimit string   `json:"time_limit,omitempty" yaml:"time_limit,omitempty" toml:"time_limit,omitempty"`
+	Parts     []string `json:"parts,omitempty" yaml:"parts,omitempty" toml:"parts,omitempty"`
+	Points    float64  `json:"points,omitempty" yaml:"points,omitempty" toml:"points,omitempty,omitzero"`
+	Category  string   `json:"category,omitempty" yaml:"category,omitempty" toml:"category,omitempty"`
+	Tags      []string `json:"tags,omitempty" yaml:"tags,omitempty" toml:"tags,omitempty"`
+	// Difficulty is either easy, medium or hard, or a level from 1 to 3
+	Difficulty string `json:"difficulty,omitempty" yaml:"difficulty,omitempty" toml:"difficulty,omitempty"`
+	Hint       string `json:"hint,omitempty" yaml:"hint,omitempty" toml:"hint,omitempty"`
+	// Markdown renders the question, and its translations, as Markdown
+	Markdown bool `json:"markdown,omitempty" yaml:"markdown,omitempty" toml:"markdown,omitempty"`
+	// Tests are the hidden Go tests of a question which is answered with code
+	Tests string `json:"tests,omitempty" yaml:"tests,omitempty" toml:"tests,omitempty"`
+	// Translations are keyed by language, eg. 'fr' or 'ja'
+	Translations map[string]translationEntry `json:"translations,omitempty" yaml:"translations,omitempty" toml:"translations,omitempty"`
+}
+
+func (entry *questionEntry) toQuestion() (Question, error) {
+	question, answer := normalize(entry.Question), normalize(entry.Answer)
+	if question == "" {
+		return Question{}, errors.New("question is empty")
+	}
+
+	var parts []string
+	for _, part := range entry.Parts {
+		if part = normalize(part); part == "" {
+			return Question{}, errors.New("part is empty")
+		}
+		if strings.Contains(part, partSeparator) {
+			return Question{}, fmt.Errorf("part %q may not contain %q", part, partSeparator)
+		}
+		parts = append(parts, part)
+	}
+	if answer == "" && len(parts) > 0 {
+		//the answer of a multi-part question defaults to all of its parts
+		answer = strings.Join(parts, partSeparator+" ")
+	}
+	var code *CodeTests
+	if strings.TrimSpace(entry.Tests) != "" {
+		var err error
+		if code, err = newCodeTests(entry.Tests); err != nil {
+			return Question{}, err
+		}
+		if len(parts) > 0 || len(entry.Options) > 0 {
+			return Question{}, errors.New("a code question can't have options or parts")
+		}
+	}
+	//a code question is scored by its tests, so its example solution is optional
+	if answer == "" && code == nil {
+		return Question{}, errors.New("answer is empty")
+	}
+	if entry.Points < 0 {
+		return Question{}, fmt.Errorf("invalid points %v", entry.Points)
+	}
+
+	var options []string
+	for _, option := range entry.Options {
+		if option = normalize(option); option == "" {
+			return Question{}, errors.New("option is empty")
+		}
+		options = append(options, option)
+	}
+	if len(options) > maxOptions {
+		return Question{}, fmt.Errorf("too many options, at most %d are allowed", maxOptions)
+	}
+	if len(options) > 0 && len(parts) > 0 {
+		return Question{}, errors.New("a multiple choice question can't have parts")
+	}
+	if len(options) > 0 {
+		//the answer may be given either as the text or the letter of the correct option
+		idx := answerIndex(options, answer)
+		if idx < 0 {
+			return Question{}, fmt.Errorf("answer %q is not one of the options", answer)
+		}
+		answer = options[idx]
+	}
+
+	var (
+		matcher Matcher
+		aliases []string
+	)
+	if strings.TrimSpace(entry.Match) != "" {
+		var err error
+		if matcher, err = ParseMatcher(entry.Match); err != nil {
+			return Question{}, err
+		}
+	}
+	for _, alias := range entry.Aliases {
+		if alias = normalize(alias); alias != "" {
+			aliases = append(aliases, alias)
+		}
+	}
+	if regex, ok := matcher.(*regexMatcher); ok && regex.pattern == nil {
+		for _, pattern := range append(append([]string{answer}, aliases...), parts...) {
+			if _, err := compileAnchored(pattern); err != nil {
+				return Question{}, fmt.Errorf("invalid regex answer: %v", err)
+			}
+		}
+	}
+
+	var tags []string
+	for _, tag := range entry.Tags {
+		if tag = normalize(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	timeLimit, err := parseTimeLimit(entry.TimeLimit)
+	if err != nil {
+		return Question{}, err
+	}
+	var difficulty Difficulty
+	if strings.TrimSpace(entry.Difficulty) != "" {
+		if difficulty, err = ParseDifficulty(entry.Difficulty); err != nil {
+			return Question{}, err
+		}
+	}
+	q := Question{
+		Text:       question,
+		Answer:     answer,
+		Options:    options,
+		Aliases:    aliases,
+		Parts:      parts,
+		Points:     entry.Points,
+		Category:   normalize(entry.Category),
+		Tags:       tags,
+		Difficulty: difficulty,
+		Hint:       normalize(entry.Hint),
+		Markdown:   entry.Markdown,
+		Code:       code,
+		Matcher:    matcher,
+		TimeLimit:  timeLimit,
+	}
+	if q.Translations, err = entry.translations(&q); err != nil {
+		return Question{}, err
+	}
+	return q, nil
+}
+
+// normalize trims a text field and puts it in NFC form, so that it does not
+// matter if the quiz file uses composed or decomposed characters
+func normalize(field string) string {
+	return norm.NFC.String(strings.TrimSpace(field))
+}
+
+func parseTimeLimit(limit string) (time.Duration, error) {
+	if limit = strings.TrimSpace(limit); limit == "" {
+		return 0, nil
+	}
+	if seconds, err := strconv.ParseFloat(limit, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	if duration, err := time.ParseDuration(limit); err == nil && duration >= 0 {
+		return duration, nil
+	}
+	return 0, fmt.Errorf("invalid time limit %q", limit)
+}
+
+type csvSource struct {
+	r io.Reader
+	// keepLeadingSpace keeps the whitespace at the start of the fields, which is
+	// trimmed by default, so that the linter can report it
+	keepLeadingSpace bool
+}
+
+type jsonSource struct {
+	data []byte
+}
+
+type yamlSource struct {
+	data []byte
+}
+
+type tomlSource struct {
+	data []byte
+}
+
+// SourceFormat returns the format of the quiz file. An explicit format always
+// wins over the one implied by the file extension
+func SourceFormat(path, format string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	switch format = strings.ToLower(format); format {
+	case formatCSV, formatJSON, formatTOML:
+		return format, nil
+	case formatYAML, "yml":
+		return formatYAML, nil
+	}
+	return "", fmt.Errorf("unsupported quiz format %q", format)
+}
+
+// NewSource creates a QuestionSource which reads questions of the given format from r
+func NewSource(format string, r io.Reader) (QuestionSource, error) {
+	if format == formatCSV {
+		return &csvSource{r: r}, nil
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read questions: %v", err)
+	}
+	switch format {
+	case formatJSON:
+		return &jsonSource{data: data}, nil
+	case formatYAML:
+		return &yamlSource{data: data}, nil
+	case formatTOML:
+		return &tomlSource{data: data}, nil
+	}
+	return nil, fmt.Errorf("unsupported quiz format %q", format)
+}
+
+// sourceEntry is a question as it was read from a source, along with its
+// position. err is set if the entry could not be decoded
+type sourceEntry struct {
+	questionEntry
+	line, column int
+	err          error
+}
+
+// entrySource is implemented by the sources which read a quiz file. It reads all entries
+// before they are validated, which allows the linter to report every problem at once
+type entrySource interface {
+	entries() ([]sourceEntry, error)
+}
+
+func readQuestions(src entrySource) ([]Question, error) {
+	entries, err := src.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	questions := make([]Question, 0, len(entries))
+	for _, entry := range entries {
+		if entry.err != nil {
+			return nil, &SourceError{Line: entry.line, Column: entry.column, Err: entry.err}
+		}
+		question, err := entry.toQuestion()
+		if err != nil {
+			return nil, &SourceError{Line: entry.line, Column: entry.column, Err: err}
+		}
+		questions = append(questions, question)
+	}
+	return questions, nil
+}
+
+// Questions reads the csv rows as 'question,answer' pairs. If the first row is a
+// header which names the columns, the rows can also include any of the other known columns
+func (src *csvSource) Questions() ([]Question, error) {
+	return readQuestions(src)
+}
+
+func (src *csvSource) entries() ([]sourceEntry, error) {
+	var (
+		entries    []sourceEntry
+		columns    map[string]int
+		quizReader = csv.NewReader(src.r)
+	)
+	quizReader.FieldsPerRecord = 0
+	quizReader.TrimLeadingSpace = !src.keepLeadingSpace
+	quizReader.ReuseRecord = true
+
+	for {
+		record, err := quizReader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && src.keepLeadingSpace && columns != nil {
+			//a quoted field can only follow whitespace when it is trimmed, the linter reports it and reads on
+			entries = append(entries, sourceEntry{line: parseErr.Line, column: parseErr.Column, err: parseErr.Err})
+			continue
+		}
+		if errors.As(err, &parseErr) {
+			return nil, &SourceError{Line: parseErr.Line, Column: parseErr.Column, Err: parseErr.Err}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if columns == nil {
+			if columns, err = csvColumns(record); err != nil {
+				line, column := quizReader.FieldPos(0)
+				return nil, &SourceError{Line: line, Column: column, Err: err}
+			}
+			if columns != nil {
+				continue
+			}
+			columns = map[string]int{csvColumnQuestion: 0, csvColumnAnswer: 1}
+		}
+
+		line, column := quizReader.FieldPos(0)
+		entry, err := csvEntry(columns, record)
+		entries = append(entries, sourceEntry{questionEntry: entry, line: line, column: column, err: err})
+	}
+
+	return entries, nil
+}
+
+// csvColumns maps the column names in the header to their index. It returns
+// nil if the row is not a header, in which case only 'question,answer' rows are allowed
+func csvColumns(header []string) (map[string]int, error) {
+	if !strings.EqualFold(strings.TrimSpace(header[0]), csvColumnQuestion) {
+		if len(header) != 2 {
+			return nil, csv.ErrFieldCount
+		}
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for idx, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if column, lang, isTranslation := strings.Cut(name, csvTranslationSeparator); isTranslation {
+			switch column {
+			case csvColumnQuestion, csvColumnAnswer, csvColumnOptions, csvColumnAliases, csvColumnParts, csvColumnHint:
+			default:
+				return nil, fmt.Errorf("column %q can't be translated", column)
+			}
+			if strings.TrimSpace(lang) == "" {
+				return nil, fmt.Errorf("missing language of column %q", column)
+			}
+		} else {
+			switch name {
+			case csvColumnQuestion, csvColumnAnswer, csvColumnOptions, csvColumnMatch, csvColumnAliases, csvColumnTimeLimit,
+				csvColumnParts, csvColumnPoints, csvColumnCategory, csvColumnTags, csvColumnDifficulty,
+				csvColumnHint, csvColumnMarkdown, csvColumnTests:
+			default:
+				return nil, fmt.Errorf("unknown column %q", name)
+			}
+		}
+		if _, found := columns[name]; found {
+			return nil, fmt.Errorf("duplicate column %q", name)
+		}
+		columns[name] = idx
+	}
+	_, hasAnswer := columns[csvColumnAnswer]
+	_, hasParts := columns[csvColumnParts]
+	if _, hasTests := columns[csvColumnTests]; !hasAnswer && !hasParts && !hasTests {
+		return nil, fmt.Errorf("missing column %q", csvColumnAnswer)
+	}
+	return columns, nil
+}
+
+func csvEntry(columns map[string]int, row []string) (questionEntry, error) {
+	field := func(name string) string {
+		if idx, found := columns[name]; found {
+			return row[idx]
+		}
+		return ""
+	}
+	entry := questionEntry{
+		Question:   field(csvColumnQuestion),
+		Answer:     field(csvColumnAnswer),
+		Options:    csvList(field(csvColumnOptions)),
+		Match:      field(csvColumnMatch),
+		Aliases:    csvList(field(csvColumnAliases)),
+		TimeLimit:  field(csvColumnTimeLimit),
+		Parts:      csvList(field(csvColumnParts)),
+		Category:   field(csvColumnCategory),
+		Tags:       csvList(field(csvColumnTags)),
+		Difficulty: field(csvColumnDifficulty),
+		Hint:       field(csvColumnHint),
+		Tests:      field(csvColumnTests),
+	}
+	for name, idx := range columns {
+		//a question which is not translated leaves the columns of the translation empty
+		column, lang, isTranslation := strings.Cut(name, csvTranslationSeparator)
+		if !isTranslation || strings.TrimSpace(row[idx]) == "" {
+			continue
+		}
+		if entry.Translations == nil {
+			entry.Translations = make(map[string]translationEntry)
+		}
+		translation := entry.Translations[lang]
+		switch column {
+		case csvColumnQuestion:
+			translation.Question = row[idx]
+		case csvColumnAnswer:
+			translation.Answer = row[idx]
+		case csvColumnOptions:
+			translation.Options = csvList(row[idx])
+		case csvColumnAliases:
+			translation.Aliases = csvList(row[idx])
+		case csvColumnParts:
+			translation.Parts = csvList(row[idx])
+		case csvColumnHint:
+			translation.Hint = row[idx]
+		}
+		entry.Translations[lang] = translation
+	}
+	if points := strings.TrimSpace(field(csvColumnPoints)); points != "" {
+		var err error
+		if entry.Points, err = strconv.ParseFloat(points, 64); err != nil {
+			return entry, fmt.Errorf("invalid points %q", points)
+		}
+	}
+	if markdown := strings.TrimSpace(field(csvColumnMarkdown)); markdown != "" {
+		var err error
+		if entry.Markdown, err = strconv.ParseBool(markdown); err != nil {
+			return entry, fmt.Errorf("invalid markdown %q", markdown)
+		}
+	}
+	return entry, nil
+}
+
+func csvList(field string) []string {
+	if strings.TrimSpace(field) == "" {
+		return nil
+	}
+	return strings.Split(field, csvListSeparator)
+}
+
+func (src *jsonSource) Questions() ([]Question, error) {
+	return readQuestions(src)
+}
+
+func (src *jsonSource) entries() ([]sourceEntry, error) {
+	var (
+		entries []sourceEntry
+		decoder = json.NewDecoder(bytes.NewReader(src.data))
+	)
+
+	if tok, err := decoder.Token(); err != nil {
+		return nil, src.positionError(err, decoder.InputOffset())
+	} else if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, src.positionError(errors.New("expected a list of questions"), 0)
+	}
+
+	for decoder.More() {
+		offset := skipSeparators(src.data, decoder.InputOffset())
+		line, column := lineColumn(src.data, offset)
+		entry := sourceEntry{line: line, column: column}
+		if err := decoder.Decode(&entry.questionEntry); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				return nil, src.positionError(err, offset)
+			}
+			//type errors are reported relative to the start of the value, and leave the decoder at its end
+			typeErr.Offset += offset
+			sourceErr := src.positionError(err, offset).(*SourceError)
+			entry.line, entry.column, entry.err = sourceErr.Line, sourceErr.Column, sourceErr.Err
+		}
+		entries = append(entries, entry)
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, src.positionError(err, decoder.InputOffset())
+	}
+	return entries, nil
+}
+
+// positionError converts err to a SourceError, preferring the offset reported
+// by the json package over the given one
+func (src *jsonSource) positionError(err error, offset int64) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	//the json package reports the offset after reading the offending byte
+	if errors.As(err, &syntaxErr) {
+		offset = syntaxErr.Offset - 1
+	} else if errors.As(err, &typeErr) {
+		offset = typeErr.Offset - 1
+	}
+	line, column := lineColumn(src.data, offset)
+	return &SourceError{Line: line, Column: column, Err: err}
+}
+
+func (src *yamlSource) Questions() ([]Question, error) {
+	return readQuestions(src)
+}
+
+func (src *yamlSource) entries() ([]sourceEntry, error) {
+	var (
+		entries []sourceEntry
+		root    yaml.Node
+	)
+	if err := yaml.Unmarshal(src.data, &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 {
+		return entries, nil
+	}
+
+	list := root.Content[0]
+	if list.Kind != yaml.SequenceNode {
+		return nil, &SourceError{Line: list.Line, Column: list.Column, Err: errors.New("expected a list of questions")}
+	}
+
+	for _, item := range list.Content {
+		entry := sourceEntry{line: item.Line, column: item.Column}
+		entry.err = item.Decode(&entry.questionEntry)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// tomlQuestionTable matches the header of each question in a toml file
+var tomlQuestionTable = regexp.MustCompile(`(?m)^[ \t]*\[\[[ \t]*questions[ \t]*]]`)
+
+func (src *tomlSource) Questions() ([]Question, error) {
+	return readQuestions(src)
+}
+
+func (src *tomlSource) entries() ([]sourceEntry, error) {
+	var (
+		entries []sourceEntry
+		doc     struct {
+			Questions []questionEntry `toml:"questions"`
+		}
+	)
+
+	if _, err := toml.Decode(string(src.data), &doc); err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			msg := parseErr.Message
+			if msg == "" {
+				msg = strings.TrimPrefix(parseErr.Error(), fmt.Sprintf("toml: line %d: ", parseErr.Position.Line))
+			}
+			line, column := lineColumn(src.data, int64(parseErr.Position.Start))
+			return nil, &SourceError{Line: line, Column: column, Err: errors.New(msg)}
+		}
+		return nil, err
+	}
+
+	tables := tomlQuestionTable.FindAllIndex(src.data, -1)
+	for idx, entry := range doc.Questions {
+		var offset int64
+		if idx < len(tables) {
+			offset = skipSeparators(src.data, int64(tables[idx][0]))
+		}
+		line, column := lineColumn(src.data, offset)
+		entries = append(entries, sourceEntry{questionEntry: entry, line: line, column: column})
+	}
+	return entries, nil
+}
+
+// skipSeparators advances offset past any whitespace or commas in data
+func skipSeparators(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// lineColumn converts a byte offset in data to a 1-based line and column
+func lineColumn(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	head := data[:offset]
+	lineStart := bytes.LastIndexByte(head, '\n') + 1
+	return bytes.Count(head, []byte{'\n'}) + 1, utf8.RuneCount(head[lineStart:]) + 1
+}
@@ -0,0 +1,200 @@
+package quiz
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSourceFormat(t *testing.T) {
+	tests := []struct {
+		path, format, want string
+	}{
+		{"problems.csv", "", formatCSV},
+		{"bank.JSON", "", formatJSON},
+		{"bank.yml", "", formatYAML},
+		{"bank.toml", "", formatTOML},
+		{"bank.txt", "yaml", formatYAML},
+	}
+	for _, tt := range tests {
+		got, err := SourceFormat(tt.path, tt.format)
+		if err != nil {
+			t.Errorf("SourceFormat(%q, %q) received an error: %v", tt.path, tt.format, err)
+		}
+		if got != tt.want {
+			t.Errorf("SourceFormat(%q, %q): want %s, got %s", tt.path, tt.format, tt.want, got)
+		}
+	}
+
+	if _, err := SourceFormat("bank.txt", ""); err == nil {
+		t.Errorf("SourceFormat(%q): expected an error", "bank.txt")
+	}
+}
+
+func TestQuestionSources(t *testing.T) {
+	tests := map[string]string{
+		formatCSV: "5+5,10\n\"what 2+2, sir?\",4\n",
+		formatJSON: `[
+  {"question": "5+5", "answer": "10"},
+  {"question": "what 2+2, sir?", "answer": "4"}
+]`,
+		formatYAML: `
+- question: 5+5
+  answer: 10
+- question: what 2+2, sir?
+  answer: 4
+`,
+		formatTOML: `
+[[questions]]
+question = "5+5"
+answer = "10"
+
+[[questions]]
+question = "what 2+2, sir?"
+answer = "4"
+`,
+	}
+
+	for format, content := range tests {
+		source, err := NewSource(format, strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("NewSource(%s) received an error: %v", format, err)
+		}
+		questions, err := source.Questions()
+		if err != nil {
+			t.Errorf("%s: Questions() received an error: %v", format, err)
+			continue
+		}
+		if len(questions) != 2 {
+			t.Errorf("%s: len(questions): want %d, got %d", format, 2, len(questions))
+			continue
+		}
+		if questions[1].Text != "what 2+2, sir?" || questions[1].Answer != "4" {
+			t.Errorf("%s: questions[1]: want %q, got %q", format, "what 2+2, sir?,4", questions[1].Text+","+questions[1].Answer)
+		}
+	}
+}
+
+func TestQuestionSources_errorPosition(t *testing.T) {
+	tests := []struct {
+		format, content string
+		line, column    int
+	}{
+		{formatCSV, "5+5,10\n1+1\n", 2, 1},
+		{formatCSV, "5+5,10\n  ,2\n", 2, 3},
+		{formatJSON, "[\n  {\"question\": \"5+5\", \"answer\": \"10\"},\n  {\"question\": \"1+1\"}\n]", 3, 3},
+		{formatJSON, "[\n  {\"question\": \"5+5\", \"answer\": 10}\n]", 2, 34},
+		{formatJSON, "[\n  {\"question\": \"5+5\" \"answer\": \"10\"}\n]", 2, 22},
+		{formatYAML, "- question: 5+5\n  answer: 10\n- question: 1+1\n", 3, 3},
+		{formatTOML, "[[questions]]\nquestion = \"5+5\"\nanswer = \"10\"\n\n[[questions]]\nquestion = \"1+1\"\n", 5, 1},
+		{formatTOML, "[[questions]]\nquestion = \"5+5\n", 2, 16},
+	}
+
+	for _, tt := range tests {
+		source, err := NewSource(tt.format, strings.NewReader(tt.content))
+		if err != nil {
+			t.Fatalf("NewSource(%s) received an error: %v", tt.format, err)
+		}
+		_, err = source.Questions()
+		var srcErr *SourceError
+		if !errors.As(err, &srcErr) {
+			t.Errorf("%s: expected a SourceError, got %v", tt.format, err)
+			continue
+		}
+		if srcErr.Line != tt.line || srcErr.Column != tt.column {
+			t.Errorf("%s: position: want %d:%d, got %d:%d (%v)", tt.format, tt.line, tt.column, srcErr.Line, srcErr.Column, err)
+		}
+	}
+}
+
+func TestQuestionSources_multipleChoice(t *testing.T) {
+	content := "question,options,answer\nCapital of France?,London|Paris|Rome,B\n"
+	source, _ := NewSource(formatCSV, strings.NewReader(content))
+	questions, err := source.Questions()
+	if err != nil {
+		t.Fatalf("Questions() received an error: %v", err)
+	}
+	if len(questions) != 1 {
+		t.Fatalf("len(questions): want %d, got %d", 1, len(questions))
+	}
+
+	record := Record{Question: questions[0]}
+	if record.Answer != "Paris" {
+		t.Errorf("record.Answer: want %s, got %s", "Paris", record.Answer)
+	}
+	for response, want := range map[string]bool{"b": true, "paris": true, "A": false, "D": false} {
+		record.Response = response
+		if got := record.IsCorrect(&textMatcher{}); got != want {
+			t.Errorf("IsCorrect() with response %q: want %t, got %t", response, want, got)
+		}
+	}
+
+	source, _ = NewSource(formatYAML, strings.NewReader("- question: 1+1\n  options: [1, 3]\n  answer: 2\n"))
+	if _, err := source.Questions(); err == nil {
+		t.Errorf("Questions(): expected an error for an answer which is not an option")
+	}
+}
+
+func TestQuestionSources_singleLetterOptions(t *testing.T) {
+	//the letter of Python is C, which is also the text of the first option
+	content := "question,options,answer\nSlowest language?,C|Java|Python,Python\nFastest language?,C|Java|Python,C\n"
+	source, _ := NewSource(formatCSV, strings.NewReader(content))
+	questions, err := source.Questions()
+	if err != nil {
+		t.Fatalf("Questions() received an error: %v", err)
+	}
+	if questions[0].Answer != "Python" || questions[1].Answer != "C" {
+		t.Errorf("Questions(): want the text of an option to win over a letter in the answer, got %q and %q", questions[0].Answer, questions[1].Answer)
+	}
+
+	tests := []struct {
+		question Question
+		response string
+		want     bool
+	}{
+		{questions[0], "C", true},
+		{questions[0], "python", true},
+		{questions[0], "A", false},
+		{questions[1], "A", true},
+		{questions[1], "C", false},
+	}
+	for _, test := range tests {
+		record := Record{Question: test.question, Response: test.response}
+		if got := record.IsCorrect(&textMatcher{}); got != test.want {
+			t.Errorf("IsCorrect() with answer %q and response %q: want %t, got %t", test.question.Answer, test.response, test.want, got)
+		}
+	}
+}
+
+func TestQuestionSources_points(t *testing.T) {
+	tests := map[string]string{
+		formatCSV:  "question,parts,points\nPrimary colours?,red|green|blue,3\n",
+		formatJSON: `[{"question": "Primary colours?", "parts": ["red", "green", "blue"], "points": 3}]`,
+		formatYAML: "- question: Primary colours?\n  parts: [red, green, blue]\n  points: 3\n",
+		formatTOML: "[[questions]]\nquestion = \"Primary colours?\"\nparts = [\"red\", \"green\", \"blue\"]\npoints = 3\n",
+	}
+
+	for format, content := range tests {
+		source, _ := NewSource(format, strings.NewReader(content))
+		questions, err := source.Questions()
+		if err != nil {
+			t.Errorf("%s: Questions() received an error: %v", format, err)
+			continue
+		}
+		question := questions[0]
+		if question.Weight() != 3 || len(question.Parts) != 3 || question.Answer != "red, green, blue" {
+			t.Errorf("%s: want 3 points for the parts %q, got %v points for %q", format, "red, green, blue", question.Weight(), question.Answer)
+		}
+	}
+
+	for _, content := range []string{
+		"question,answer,points\n5+5,10,ten\n",
+		"question,answer,points\n5+5,10,-1\n",
+		"question,options,parts,answer\nPick,a|b,a|b,a\n",
+	} {
+		source, _ := NewSource(formatCSV, strings.NewReader(content))
+		if _, err := source.Questions(); err == nil {
+			t.Errorf("Questions(): expected an error for %q", content)
+		}
+	}
+}
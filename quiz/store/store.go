@@ -0,0 +1,148 @@
+// Package store keeps the quiz attempts and the spaced repetition schedules of
+// every user in a bolt database
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	bolt "go.etcd.io/bbolt"
+	"gophercises.com/quiz"
+	"time"
+)
+
+const (
+	historyBucketName = "History"
+	reviewsBucketName = "Reviews"
+)
+
+// Attempt is a single quiz session taken by a user
+type Attempt struct {
+	User   string       `json:"user"`
+	Quiz   string       `json:"quiz"`
+	Time   time.Time    `json:"time"`
+	Report *quiz.Report `json:"report"`
+}
+
+// Store keeps the attempts of every user in a bolt database, with a bucket for each user
+type Store struct {
+	db *bolt.DB
+}
+
+func Open(dbPath string) (*Store, error) {
+	db, err := bolt.Open(dbPath, 0666, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open db file %s: %v", dbPath, err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (store *Store) Close() error {
+	return store.db.Close()
+}
+
+func (store *Store) Record(attempt *Attempt) error {
+	value, err := json.Marshal(attempt)
+	if err != nil {
+		return err
+	}
+	return store.db.Update(func(tx *bolt.Tx) error {
+		history, err := tx.CreateBucketIfNotExists([]byte(historyBucketName))
+		if err != nil {
+			return err
+		}
+		bucket, err := history.CreateBucketIfNotExists([]byte(attempt.User))
+		if err != nil {
+			return err
+		}
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		return bucket.Put(itob(seq), value)
+	})
+}
+
+// Attempts returns all attempts of the user, oldest first
+func (store *Store) Attempts(user string) ([]Attempt, error) {
+	var attempts []Attempt
+	err := store.db.View(func(tx *bolt.Tx) error {
+		history := tx.Bucket([]byte(historyBucketName))
+		if history == nil {
+			return nil
+		}
+		bucket := history.Bucket([]byte(user))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(_, v []byte) error {
+			var attempt Attempt
+			if err := json.Unmarshal(v, &attempt); err != nil {
+				return fmt.Errorf("failed to decode attempt: %v", err)
+			}
+			attempts = append(attempts, attempt)
+			return nil
+		})
+	})
+	return attempts, err
+}
+
+// reviewKey identifies a question of a quiz in the review bucket of a user
+func reviewKey(quizName, question string) []byte {
+	return []byte(quizName + "\x00" + question)
+}
+
+// Reviews returns the review schedule of the user for the given records, keyed by question
+func (store *Store) Reviews(user, quizName string, records []quiz.Record) (map[string]quiz.ReviewState, error) {
+	states := make(map[string]quiz.ReviewState)
+	err := store.db.View(func(tx *bolt.Tx) error {
+		reviews := tx.Bucket([]byte(reviewsBucketName))
+		if reviews == nil {
+			return nil
+		}
+		bucket := reviews.Bucket([]byte(user))
+		if bucket == nil {
+			return nil
+		}
+		for _, record := range records {
+			if v := bucket.Get(reviewKey(quizName, record.Text)); v != nil {
+				var state quiz.ReviewState
+				if err := json.Unmarshal(v, &state); err != nil {
+					return fmt.Errorf("failed to decode review state: %v", err)
+				}
+				states[record.Text] = state
+			}
+		}
+		return nil
+	})
+	return states, err
+}
+
+func (store *Store) SaveReviews(user, quizName string, states map[string]quiz.ReviewState) error {
+	return store.db.Update(func(tx *bolt.Tx) error {
+		reviews, err := tx.CreateBucketIfNotExists([]byte(reviewsBucketName))
+		if err != nil {
+			return err
+		}
+		bucket, err := reviews.CreateBucketIfNotExists([]byte(user))
+		if err != nil {
+			return err
+		}
+		for question, state := range states {
+			value, err := json.Marshal(state)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put(reviewKey(quizName, question), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"gophercises.com/quiz"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openTestStore opens a store in a temporary bolt file, which is closed with the test
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "quiz.db"))
+	if err != nil {
+		t.Fatalf("Open() received an error: %v", err)
+	}
+	t.Cleanup(func() {
+		//goland:noinspection GoUnhandledErrorResult
+		store.Close()
+	})
+	return store
+}
+
+func newAttempt(user, quizName string, responses ...string) *Attempt {
+	var records []quiz.Record
+	for _, response := range responses {
+		records = append(records, quiz.Record{Question: quiz.Question{Text: "1+1", Answer: "2"}, Asked: true, Answered: true, Response: response})
+	}
+	matcher, _ := quiz.ParseMatcher(quiz.DefaultMatch)
+	return &Attempt{User: user, Quiz: quizName, Time: time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC), Report: quiz.NewReport(records, matcher, 0)}
+}
+
+func TestStore_Attempts(t *testing.T) {
+	store := openTestStore(t)
+	if attempts, err := store.Attempts("ann"); err != nil || len(attempts) != 0 {
+		t.Errorf("Attempts(): want no attempts in a new store, got %v (%v)", attempts, err)
+	}
+
+	for _, attempt := range []*Attempt{newAttempt("ann", "first", "3"), newAttempt("bob", "first", "2"), newAttempt("ann", "second", "2", "2")} {
+		if err := store.Record(attempt); err != nil {
+			t.Fatalf("Record() received an error: %v", err)
+		}
+	}
+	attempts, err := store.Attempts("ann")
+	if err != nil {
+		t.Fatalf("Attempts() received an error: %v", err)
+	}
+	if len(attempts) != 2 || attempts[0].Quiz != "first" || attempts[1].Quiz != "second" {
+		t.Fatalf("Attempts(): want the attempts of ann oldest first, got %+v", attempts)
+	}
+	if report := attempts[1].Report; report.Correct != 2 || report.Total != 2 || len(report.Questions) != 2 {
+		t.Errorf("Attempts(): want the report to be kept, got %+v", report)
+	}
+	if !attempts[0].Time.Equal(time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Attempts(): want the time of the attempt to be kept, got %v", attempts[0].Time)
+	}
+}
+
+func TestStore_Reviews(t *testing.T) {
+	store := openTestStore(t)
+	records := []quiz.Record{{Question: quiz.Question{Text: "1+1"}}, {Question: quiz.Question{Text: "2+2"}}}
+	if states, err := store.Reviews("ann", "math", records); err != nil || len(states) != 0 {
+		t.Errorf("Reviews(): want no reviews in a new store, got %v (%v)", states, err)
+	}
+
+	due := time.Date(2022, 10, 2, 0, 0, 0, 0, time.UTC)
+	if err := store.SaveReviews("ann", "math", map[string]quiz.ReviewState{"1+1": {Repetitions: 1, Interval: 1, Ease: 2.6, Due: due}}); err != nil {
+		t.Fatalf("SaveReviews() received an error: %v", err)
+	}
+	if err := store.SaveReviews("ann", "other", map[string]quiz.ReviewState{"2+2": {Repetitions: 2}}); err != nil {
+		t.Fatalf("SaveReviews() received an error: %v", err)
+	}
+	if err := store.SaveReviews("bob", "math", map[string]quiz.ReviewState{"2+2": {Repetitions: 3}}); err != nil {
+		t.Fatalf("SaveReviews() received an error: %v", err)
+	}
+
+	states, err := store.Reviews("ann", "math", records)
+	if err != nil {
+		t.Fatalf("Reviews() received an error: %v", err)
+	}
+	if len(states) != 1 {
+		t.Fatalf("Reviews(): want only the reviews of ann for the quiz, got %v", states)
+	}
+	if state := states["1+1"]; state.Repetitions != 1 || state.Interval != 1 || state.Ease != 2.6 || !state.Due.Equal(due) {
+		t.Errorf("Reviews(): want the saved schedule, got %+v", state)
+	}
+
+	//saving again updates the schedule
+	if err := store.SaveReviews("ann", "math", map[string]quiz.ReviewState{"1+1": {Repetitions: 2, Interval: 6}}); err != nil {
+		t.Fatalf("SaveReviews() received an error: %v", err)
+	}
+	if states, _ = store.Reviews("ann", "math", records); states["1+1"].Interval != 6 {
+		t.Errorf("Reviews(): want the updated schedule, got %+v", states["1+1"])
+	}
+}
@@ -0,0 +1,109 @@
+package quiz
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	defaultEase = 2.5
+	minEase     = 1.3
+	day         = 24 * time.Hour
+	// quickAnswer is the time under which a correct answer counts as a perfect recall
+	quickAnswer = 5 * time.Second
+)
+
+// ReviewState is the spaced repetition schedule of a single question for a user
+type ReviewState struct {
+	Repetitions int       `json:"repetitions"`
+	Interval    int       `json:"interval"`
+	Ease        float64   `json:"ease"`
+	Due         time.Time `json:"due"`
+}
+
+// NewReviewState is the schedule of a question which was never reviewed
+func NewReviewState() ReviewState {
+	return ReviewState{Ease: defaultEase}
+}
+
+// Review updates the schedule with the quality of the recall, from 0 (complete
+// blackout) to 5 (perfect response), following the SM-2 algorithm
+func (state *ReviewState) Review(quality int, now time.Time) {
+	if quality >= 3 {
+		switch state.Repetitions {
+		case 0:
+			state.Interval = 1
+		case 1:
+			state.Interval = 6
+		default:
+			state.Interval = int(math.Round(float64(state.Interval) * state.Ease))
+		}
+		state.Repetitions++
+	} else {
+		state.Repetitions = 0
+		state.Interval = 1
+	}
+
+	lapse := float64(5 - quality)
+	state.Ease = math.Max(minEase, state.Ease+0.1-lapse*(0.08+lapse*0.02))
+	state.Due = now.Add(time.Duration(state.Interval) * day)
+}
+
+// RecallQuality grades the response to a question for the spaced repetition schedule
+func RecallQuality(record *Record, matcher Matcher) int {
+	switch {
+	case !record.Answered:
+		return 0
+	case !record.IsCorrect(matcher):
+		return 2
+	case record.Elapsed <= quickAnswer:
+		return 5
+	}
+	return 4
+}
+
+// ReviewQuestions reschedules every question which was asked during a study
+// session. A question which was left when the session was interrupted or the
+// input ended was not asked, and keeps its schedule
+func ReviewQuestions(records []Record, states map[string]ReviewState, matcher Matcher, now time.Time) map[string]ReviewState {
+	reviewed := make(map[string]ReviewState)
+	for idx := range records {
+		record := &records[idx]
+		if !record.Asked {
+			continue
+		}
+		state, found := states[record.Text]
+		if !found {
+			state = NewReviewState()
+		}
+		state.Review(RecallQuality(record, matcher), now)
+		reviewed[record.Text] = state
+	}
+	return reviewed
+}
+
+// DueQuestions picks the questions which are due for review, the most overdue
+// first, followed by the questions which were never reviewed. It also returns
+// when the next question will be due if none are due now
+func DueQuestions(records []Record, states map[string]ReviewState, now time.Time) ([]Record, time.Time) {
+	var (
+		due, unseen []Record
+		nextDue     time.Time
+	)
+	for _, record := range records {
+		state, found := states[record.Text]
+		switch {
+		case !found:
+			unseen = append(unseen, record)
+		case !state.Due.After(now):
+			due = append(due, record)
+		case nextDue.IsZero() || state.Due.Before(nextDue):
+			nextDue = state.Due
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return states[due[i].Text].Due.Before(states[due[j].Text].Due)
+	})
+	return append(due, unseen...), nextDue
+}
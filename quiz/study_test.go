@@ -0,0 +1,86 @@
+package quiz
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReviewState_review(t *testing.T) {
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	state := NewReviewState()
+
+	for _, want := range []int{1, 6, 16} {
+		state.Review(5, now)
+		if state.Interval != want {
+			t.Errorf("state.Interval: want %d, got %d", want, state.Interval)
+		}
+	}
+	if want := now.Add(16 * day); !state.Due.Equal(want) {
+		t.Errorf("state.Due: want %v, got %v", want, state.Due)
+	}
+
+	state.Review(0, now)
+	if state.Repetitions != 0 || state.Interval != 1 {
+		t.Errorf("after a lapse: want 0 repetitions and an interval of 1, got %d and %d", state.Repetitions, state.Interval)
+	}
+	for i := 0; i < 10; i++ {
+		state.Review(0, now)
+	}
+	if state.Ease != minEase {
+		t.Errorf("state.Ease: want %.1f, got %.2f", minEase, state.Ease)
+	}
+}
+
+func TestDueQuestions(t *testing.T) {
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	records := []Record{
+		{Question: Question{Text: "new"}},
+		{Question: Question{Text: "later"}},
+		{Question: Question{Text: "due"}},
+		{Question: Question{Text: "overdue"}},
+	}
+	states := map[string]ReviewState{
+		"later":   {Due: now.Add(day)},
+		"due":     {Due: now},
+		"overdue": {Due: now.Add(-day)},
+	}
+
+	due, nextDue := DueQuestions(records, states, now)
+	var questions []string
+	for _, record := range due {
+		questions = append(questions, record.Text)
+	}
+	if got := len(questions); got != 3 || questions[0] != "overdue" || questions[1] != "due" || questions[2] != "new" {
+		t.Errorf("DueQuestions: want [overdue due new], got %v", questions)
+	}
+	if !nextDue.Equal(now.Add(day)) {
+		t.Errorf("nextDue: want %v, got %v", now.Add(day), nextDue)
+	}
+}
+
+func TestReviewQuestions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	records := []Record{
+		{Question: Question{Text: "5+5", Answer: "10"}},
+		{Question: Question{Text: "1+1", Answer: "2"}},
+		{Question: Question{Text: "8+3", Answer: "11"}},
+	}
+	states := map[string]ReviewState{"1+1": {Repetitions: 3, Interval: 16, Ease: defaultEase, Due: now}}
+
+	//the input ends while the second question is shown
+	session := newTestSession(ctx, strings.NewReader("10\n"), records)
+	for range session.Start(ctx) {
+	}
+	reviewed := ReviewQuestions(session.Records, states, &textMatcher{}, now)
+	if len(reviewed) != 1 {
+		t.Fatalf("ReviewQuestions(): want only the answered question to be rescheduled, got %v", reviewed)
+	}
+	if state := reviewed["5+5"]; state.Repetitions != 1 || state.Interval != 1 {
+		t.Errorf("ReviewQuestions(): want the answered question to be reviewed once, got %+v", state)
+	}
+}
@@ -0,0 +1,151 @@
+package quiz
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/text/language"
+	"strings"
+)
+
+// Translation is a question in another language. Only the text is translated,
+// the points, time limit and other settings of the question stay the same
+type Translation struct {
+	Text    string
+	Answer  string
+	Options []string
+	Aliases []string
+	Parts   []string
+	Hint    string
+}
+
+// translationEntry is the shape of a translation in a quiz file. The answer may
+// be left out when it is the same in every language, eg. a number, and the
+// options and parts when they are not translated
+type translationEntry struct {
+	Question string   `json:"question" yaml:"question" toml:"question"`
+	Answer   string   `json:"answer,omitempty" yaml:"answer,omitempty" toml:"answer,omitempty"`
+	Options  []string `json:"options,omitempty" yaml:"options,omitempty" toml:"options,omitempty"`
+	Aliases  []string `json:"aliases,omitempty" yaml:"aliases,omitempty" toml:"aliases,omitempty"`
+	Parts    []string `json:"parts,omitempty" yaml:"parts,omitempty" toml:"parts,omitempty"`
+	Hint     string   `json:"hint,omitempty" yaml:"hint,omitempty" toml:"hint,omitempty"`
+}
+
+// translations validates the translations of the entry against its question
+func (entry *questionEntry) translations(question *Question) (map[string]Translation, error) {
+	if len(entry.Translations) == 0 {
+		return nil, nil
+	}
+	translations := make(map[string]Translation)
+	for lang, translation := range entry.Translations {
+		tag, err := language.Parse(strings.TrimSpace(lang))
+		if err != nil {
+			return nil, fmt.Errorf("invalid language %q", lang)
+		}
+		if translations[tag.String()], err = translation.toTranslation(question); err != nil {
+			return nil, fmt.Errorf("%s translation: %v", tag, err)
+		}
+	}
+	return translations, nil
+}
+
+func (entry *translationEntry) toTranslation(question *Question) (Translation, error) {
+	translation := Translation{Text: normalize(entry.Question), Hint: normalize(entry.Hint)}
+	if translation.Text == "" {
+		return Translation{}, errors.New("question is empty")
+	}
+
+	var err error
+	if translation.Options, err = translateList("options", entry.Options, question.Options); err != nil {
+		return Translation{}, err
+	}
+	if translation.Parts, err = translateList("parts", entry.Parts, question.Parts); err != nil {
+		return Translation{}, err
+	}
+	for _, part := range translation.Parts {
+		if strings.Contains(part, partSeparator) {
+			return Translation{}, fmt.Errorf("part %q may not contain %q", part, partSeparator)
+		}
+	}
+	for _, alias := range entry.Aliases {
+		if alias = normalize(alias); alias != "" {
+			translation.Aliases = append(translation.Aliases, alias)
+		}
+	}
+
+	answer := normalize(entry.Answer)
+	switch {
+	case question.IsMultipleChoice():
+		//the answer has to be the same option as in the question, which is the default
+		want := answerIndex(question.Options, question.Answer)
+		if answer == "" {
+			answer = translation.Options[want]
+		}
+		if idx := answerIndex(translation.Options, answer); idx != want {
+			return Translation{}, fmt.Errorf("answer %q is not the option %s", answer, OptionLetter(want))
+		}
+		answer = translation.Options[want]
+	case answer == "" && question.IsMultiPart():
+		answer = strings.Join(translation.Parts, partSeparator+" ")
+	case answer == "":
+		answer = question.Answer
+	}
+	translation.Answer = answer
+
+	if regex, ok := question.Matcher.(*regexMatcher); ok && regex.pattern == nil {
+		for _, pattern := range append(append([]string{answer}, translation.Aliases...), translation.Parts...) {
+			if _, err := compileAnchored(pattern); err != nil {
+				return Translation{}, fmt.Errorf("invalid regex answer: %v", err)
+			}
+		}
+	}
+	return translation, nil
+}
+
+// translateList checks that a translated list has as many items as the original
+// one. A list which is not translated is kept as it is
+func translateList(name string, translated, original []string) ([]string, error) {
+	if len(translated) == 0 {
+		return original, nil
+	}
+	if len(translated) != len(original) {
+		return nil, fmt.Errorf("%d %s instead of %d", len(translated), name, len(original))
+	}
+	var list []string
+	for _, item := range translated {
+		if item = normalize(item); item == "" {
+			return nil, fmt.Errorf("%s has an empty item", name)
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// Translate returns the quiz in the given language, eg. 'fr' or 'pt-BR'. A question
+// without a translation to the language, or to its base language, eg. 'pt' for
+// 'pt-BR', is left as it is. It fails if no question was translated at all
+func (quiz *Quiz) Translate(lang string) (*Quiz, error) {
+	tag, err := language.Parse(strings.TrimSpace(lang))
+	if err != nil {
+		return nil, fmt.Errorf("invalid language %q", lang)
+	}
+	base, _ := tag.Base()
+
+	translated := &Quiz{}
+	found := false
+	for _, question := range quiz.Questions {
+		translation, ok := question.Translations[tag.String()]
+		if !ok {
+			translation, ok = question.Translations[base.String()]
+		}
+		if ok {
+			question.Text, question.Answer, question.Hint = translation.Text, translation.Answer, translation.Hint
+			question.Options, question.Aliases, question.Parts = translation.Options, translation.Aliases, translation.Parts
+			found = true
+		}
+		translated.Questions = append(translated.Questions, question)
+	}
+	if !found {
+		return nil, fmt.Errorf("the quiz has no translations to %s", tag)
+	}
+	return translated, nil
+}
@@ -0,0 +1,79 @@
+package quiz
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQuestionSources_translations(t *testing.T) {
+	tests := map[string]string{
+		formatCSV: "question,options,answer,question:fr,options:fr\n" +
+			"Pick the even number,one|two|three,b,Choisissez le nombre pair,un|deux|trois\nUntranslated?,,yes,,\n",
+		formatJSON: `[{"question": "Pick the even number", "options": ["one", "two", "three"], "answer": "b",` +
+			` "translations": {"fr": {"question": "Choisissez le nombre pair", "options": ["un", "deux", "trois"]}}},` +
+			` {"question": "Untranslated?", "answer": "yes"}]`,
+		formatYAML: "- question: Pick the even number\n  options: [one, two, three]\n  answer: b\n  translations:\n" +
+			"    fr:\n      question: Choisissez le nombre pair\n      options: [un, deux, trois]\n" +
+			"- question: Untranslated?\n  answer: yes\n",
+		formatTOML: "[[questions]]\nquestion = \"Pick the even number\"\noptions = [\"one\", \"two\", \"three\"]\nanswer = \"b\"\n" +
+			"[questions.translations.fr]\nquestion = \"Choisissez le nombre pair\"\noptions = [\"un\", \"deux\", \"trois\"]\n" +
+			"[[questions]]\nquestion = \"Untranslated?\"\nanswer = \"yes\"\n",
+	}
+
+	for format, content := range tests {
+		source, _ := NewSource(format, strings.NewReader(content))
+		q, err := FromSource(source)
+		if err != nil {
+			t.Errorf("%s: FromSource() received an error: %v", format, err)
+			continue
+		}
+		translated, err := q.Translate("fr-CA")
+		if err != nil {
+			t.Errorf("%s: Translate() received an error: %v", format, err)
+			continue
+		}
+		question := translated.Questions[0]
+		if question.Text != "Choisissez le nombre pair" || question.Answer != "deux" || strings.Join(question.Options, "|") != "un|deux|trois" {
+			t.Errorf("%s: Translate(): want the question in french, got %q with the answer %q", format, question.Text, question.Answer)
+		}
+		if translated.Questions[1].Text != "Untranslated?" {
+			t.Errorf("%s: Translate(): want an untranslated question to be kept, got %q", format, translated.Questions[1].Text)
+		}
+		if q.Questions[0].Text != "Pick the even number" {
+			t.Errorf("%s: Translate(): the original quiz should not change", format)
+		}
+		if _, err := q.Translate("de"); err == nil {
+			t.Errorf("%s: Translate(): want an error for a language without translations", format)
+		}
+	}
+}
+
+func TestQuestionSources_translationErrors(t *testing.T) {
+	for _, content := range []string{
+		`[{"question": "Pick", "options": ["a", "b"], "answer": "a", "translations": {"fr": {"question": "Choisir", "options": ["x"]}}}]`,
+		`[{"question": "Pick", "options": ["a", "b"], "answer": "a", "translations": {"fr": {"question": "Choisir", "answer": "b"}}}]`,
+		`[{"question": "Dog", "answer": "dog", "translations": {"fr": {"answer": "chien"}}}]`,
+		`[{"question": "Dog", "answer": "dog", "translations": {"??": {"question": "Chien"}}}]`,
+	} {
+		source, _ := NewSource(formatJSON, strings.NewReader(content))
+		if _, err := source.Questions(); err == nil {
+			t.Errorf("Questions(): expected an error for %s", content)
+		}
+	}
+
+	source, _ := NewSource(formatCSV, strings.NewReader("question,answer,time_limit:fr\nDog,dog,10\n"))
+	if _, err := source.Questions(); err == nil {
+		t.Errorf("Questions(): expected an error for a column which can't be translated")
+	}
+}
+
+func TestQuestionSources_normalized(t *testing.T) {
+	source, _ := NewSource(formatCSV, strings.NewReader("question,answer\nCoffee?,cafe\u0301\n"))
+	questions, err := source.Questions()
+	if err != nil {
+		t.Fatalf("Questions() received an error: %v", err)
+	}
+	if questions[0].Answer != "caf\u00e9" {
+		t.Errorf("Questions(): want the answer in NFC form, got %q", questions[0].Answer)
+	}
+}
@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"gophercises.com/quiz"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	keyCtrlC     = 0x03
+	keyBackspace = 0x08
+	keyCtrlU     = 0x15
+	keyCtrlZ     = 0x1a
+	keyEscape    = 0x1b
+	keyDelete    = 0x7f
+)
+
+// readKeys handles the keys pressed until the input is closed. The terminal
+// is in raw mode, so the responses are edited here rather than by the terminal
+func (screen *Screen) readKeys() {
+	buf := make([]byte, 256)
+	for {
+		n, err := screen.in.Read(buf)
+		if err != nil {
+			return
+		}
+		screen.handleKeys(buf[:n])
+	}
+}
+
+func (screen *Screen) handleKeys(keys []byte) {
+	for len(keys) > 0 {
+		switch key := keys[0]; key {
+		case keyCtrlC:
+			if screen.opt.Interrupt != nil {
+				screen.opt.Interrupt()
+			}
+		case keyCtrlZ:
+			select {
+			case screen.pause <- struct{}{}:
+			default:
+			}
+		case '\r', '\n':
+			screen.enter()
+		case keyBackspace, keyDelete:
+			screen.edit(func(buffer []rune) []rune {
+				if len(buffer) == 0 {
+					return buffer
+				}
+				return buffer[:len(buffer)-1]
+			})
+		case keyCtrlU:
+			screen.edit(func(buffer []rune) []rune { return buffer[:0] })
+		case keyEscape:
+			keys = screen.escape(keys[1:])
+			continue
+		default:
+			r, size := utf8.DecodeRune(keys)
+			if r == 'q' {
+				screen.leave()
+			}
+			if unicode.IsPrint(r) {
+				screen.edit(func(buffer []rune) []rune { return append(buffer, r) })
+			}
+			keys = keys[size:]
+			continue
+		}
+		keys = keys[1:]
+	}
+}
+
+// escape handles an escape sequence, of which only the arrow and page keys are
+// used to scroll the history, and the focus reports are logged. A lone escape leaves the review. It returns the
+// keys which follow the sequence
+func (screen *Screen) escape(keys []byte) []byte {
+	if len(keys) == 0 || keys[0] != '[' {
+		screen.leave()
+		return keys
+	}
+	end := 1
+	for end < len(keys) && (keys[end] < 0x40 || keys[end] > 0x7e) {
+		end++
+	}
+	if end == len(keys) {
+		return nil
+	}
+	switch string(keys[1 : end+1]) {
+	case "A":
+		screen.scrollBy(-1)
+	case "B":
+		screen.scrollBy(1)
+	case "5~":
+		screen.scrollBy(-10)
+	case "6~":
+		screen.scrollBy(10)
+	case "O":
+		screen.logFocus(quiz.EventFocusLost)
+	case "I":
+		screen.logFocus(quiz.EventFocusGained)
+	}
+	return keys[end+1:]
+}
+
+// enter submits the response, or leaves the review once the quiz is over
+func (screen *Screen) enter() {
+	screen.mu.Lock()
+	over, line := screen.over, string(screen.buffer)
+	screen.buffer = screen.buffer[:0]
+	screen.mu.Unlock()
+	if over {
+		screen.leave()
+		return
+	}
+	select {
+	case screen.submitted <- line:
+	default:
+	}
+}
+
+func (screen *Screen) leave() {
+	screen.mu.Lock()
+	over := screen.over
+	screen.mu.Unlock()
+	if over {
+		screen.exitOnce.Do(func() { close(screen.exit) })
+	}
+}
+
+func (screen *Screen) edit(change func(buffer []rune) []rune) {
+	screen.mu.Lock()
+	defer screen.mu.Unlock()
+	if !screen.over {
+		screen.buffer = change(screen.buffer)
+	}
+}
+
+func (screen *Screen) scrollBy(rows int) {
+	screen.mu.Lock()
+	defer screen.mu.Unlock()
+	//the bounds depend on the size of the screen and are checked by layout
+	screen.scroll += rows
+}
+
+// logFocus records a change of focus on the current question
+func (screen *Screen) logFocus(kind string) {
+	screen.mu.Lock()
+	number := screen.number
+	screen.mu.Unlock()
+	screen.opt.Log.Add(kind, number, "")
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"testing"
+)
+
+func TestScreen_handleKeys(t *testing.T) {
+	screen := newTestScreen()
+	var interrupted bool
+	screen.opt.Interrupt = func() { interrupted = true }
+
+	screen.handleKeys([]byte("4x\x7f2"))
+	if got := string(screen.buffer); got != "42" {
+		t.Errorf("handleKeys(): want the response to be edited, got %q", got)
+	}
+	screen.handleKeys([]byte("\r"))
+	if got := <-screen.submitted; got != "42" || len(screen.buffer) != 0 {
+		t.Errorf("handleKeys(): want enter to submit %q, got %q with %q left", "42", got, string(screen.buffer))
+	}
+
+	screen.handleKeys([]byte("\u6771\u4eac\x15quit\x08\x08"))
+	if got := string(screen.buffer); got != "qu" {
+		t.Errorf("handleKeys(): want Ctrl-U to clear the response and backspace to remove whole characters, got %q", got)
+	}
+	screen.handleKeys([]byte{keyCtrlC, keyCtrlZ})
+	if !interrupted {
+		t.Errorf("handleKeys(): want Ctrl-C to interrupt the quiz")
+	}
+	select {
+	case <-screen.pause:
+	default:
+		t.Errorf("handleKeys(): want Ctrl-Z to pause the quiz")
+	}
+
+	screen.handleKeys([]byte("\x1b[B\x1b[B\x1b[A\x1b[6~"))
+	if screen.scroll != 11 {
+		t.Errorf("handleKeys(): want the arrow and page keys to scroll, got %d", screen.scroll)
+	}
+	//an incomplete sequence is dropped, and an unknown one is skipped without typing it
+	screen.handleKeys([]byte("\x1b[1;5Da\x1b[12"))
+	if got := string(screen.buffer); got != "qua" {
+		t.Errorf("handleKeys(): want the escape sequences to be left out of the response, got %q", got)
+	}
+	if isClosed(screen.exit) {
+		t.Errorf("handleKeys(): want q and escape to be typed while the quiz is running")
+	}
+}
+
+func TestScreen_handleKeys_review(t *testing.T) {
+	for _, key := range []string{"q", "\r", "\x1b"} {
+		screen := newTestScreen()
+		screen.buffer = []rune("4")
+		screen.over = true
+		screen.handleKeys([]byte("2" + key))
+		if !isClosed(screen.exit) {
+			t.Errorf("handleKeys(%q): want the key to leave the review", key)
+		}
+		if got := string(screen.buffer); got == "42" {
+			t.Errorf("handleKeys(%q): want the response not to change once the quiz is over, got %q", key, got)
+		}
+	}
+}
+
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,445 @@
+// Package tui shows a quiz session full screen in a terminal, with a live
+// countdown, the progress of the quiz and a history of the responses
+package tui
+
+import (
+	"context"
+	"fmt"
+	"golang.org/x/term"
+	"golang.org/x/text/width"
+	"gophercises.com/quiz"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	refreshRate   = 200 * time.Millisecond
+	progressWidth = 20
+	// messageTime is how long a message about the previous question stays on screen
+	messageTime = 3 * time.Second
+	// warnTime is when the countdown turns red
+	warnTime = 10 * time.Second
+
+	defaultWidth  = 80
+	defaultHeight = 24
+
+	enterAltScreen = "\x1b[?1049h"
+	leaveAltScreen = "\x1b[?1049l"
+	reportFocus    = "\x1b[?1004h"
+	ignoreFocus    = "\x1b[?1004l"
+	home           = "\x1b[H"
+	clearLine      = "\x1b[K"
+	clearBelow     = "\x1b[J"
+	red            = "\x1b[31m"
+	bold           = "\x1b[1m"
+	reset          = "\x1b[0m"
+)
+
+// Option are the settings of a Screen
+type Option struct {
+	// Matcher and Penalty score the responses as they are given
+	Matcher quiz.Matcher
+	Penalty float64
+	// ShowScore shows the score and the result of each response while the quiz is running
+	ShowScore bool
+	// Interrupt is called on Ctrl-C, since the terminal no longer sends a signal for it
+	Interrupt func()
+	// Log records when the terminal loses and regains the focus, for a proctored
+	// session. Not every terminal reports it
+	Log *quiz.EventLog
+}
+
+// Screen is a full screen display of a quiz session. It is both the input and
+// the output of the session: it edits the responses and shows the questions
+// along with any message of the session
+type Screen struct {
+	opt     Option
+	in, out *os.File
+	state   *term.State
+
+	input     *io.PipeReader
+	submitted chan string
+	pause     chan struct{}
+	exit      chan struct{}
+	done      chan struct{}
+	rendered  chan struct{}
+	closeOnce sync.Once
+	exitOnce  sync.Once
+
+	mu               sync.Mutex
+	total            int
+	deadline         time.Time
+	number           int
+	question         *quiz.Question
+	questionDeadline time.Time
+	buffer           []rune
+	message          string
+	messageExpiry    time.Time
+	records          []quiz.Record
+	report           *quiz.Report
+	scroll           int
+	over             bool
+}
+
+// IsTerminal checks if both the input and the output are a terminal, which is
+// required to show the quiz full screen
+func IsTerminal(in, out *os.File) bool {
+	return term.IsTerminal(int(in.Fd())) && term.IsTerminal(int(out.Fd()))
+}
+
+// Open switches the terminal to full screen. The terminal is only restored by Close
+func Open(in, out *os.File, opt Option) (*Screen, error) {
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to switch the terminal to full screen: %v", err)
+	}
+
+	reader, writer := io.Pipe()
+	screen := &Screen{
+		opt:       opt,
+		in:        in,
+		out:       out,
+		state:     state,
+		input:     reader,
+		submitted: make(chan string, 16),
+		pause:     make(chan struct{}, 1),
+		exit:      make(chan struct{}),
+		done:      make(chan struct{}),
+		rendered:  make(chan struct{}),
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	out.WriteString(enterAltScreen)
+	if opt.Log != nil {
+		//goland:noinspection GoUnhandledErrorResult
+		out.WriteString(reportFocus)
+	}
+
+	//the responses are passed on from their own goroutine so that the keys are
+	//still handled while the session is not reading
+	go func() {
+		defer writer.Close()
+		for {
+			select {
+			case line := <-screen.submitted:
+				if _, err := io.WriteString(writer, line+"\n"); err != nil {
+					return
+				}
+			case <-screen.done:
+				return
+			}
+		}
+	}()
+	go screen.readKeys()
+	go screen.refresh()
+	return screen, nil
+}
+
+// Close stops the display and restores the terminal
+func (screen *Screen) Close() error {
+	var err error
+	screen.closeOnce.Do(func() {
+		close(screen.done)
+		<-screen.rendered
+		//goland:noinspection GoUnhandledErrorResult
+		screen.out.WriteString(reset + leaveAltScreen)
+		if screen.opt.Log != nil {
+			//goland:noinspection GoUnhandledErrorResult
+			screen.out.WriteString(ignoreFocus)
+		}
+		err = term.Restore(int(screen.in.Fd()), screen.state)
+	})
+	return err
+}
+
+// Read reads the responses, one line at a time, for the InputReader of the session
+func (screen *Screen) Read(p []byte) (int, error) {
+	return screen.input.Read(p)
+}
+
+// Write shows the last line of a message of the session, eg. that the time is up
+func (screen *Screen) Write(p []byte) (int, error) {
+	screen.mu.Lock()
+	defer screen.mu.Unlock()
+	for _, line := range strings.Split(string(p), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			screen.message, screen.messageExpiry = line, time.Time{}
+		}
+	}
+	return len(p), nil
+}
+
+// Prompt shows the next question
+func (screen *Screen) Prompt(number int, question quiz.Question, deadline time.Time) {
+	screen.mu.Lock()
+	defer screen.mu.Unlock()
+	//a message about the previous question only stays for a moment
+	if screen.question != nil && screen.messageExpiry.IsZero() {
+		screen.messageExpiry = time.Now().Add(messageTime)
+	} else if screen.question == nil {
+		screen.message = ""
+	}
+	screen.number, screen.question, screen.questionDeadline = number, &question, deadline
+	screen.buffer = screen.buffer[:0]
+}
+
+// Pause is sent on Ctrl-Z, since the terminal no longer sends a signal for it
+func (screen *Screen) Pause() <-chan struct{} {
+	return screen.pause
+}
+
+// Start starts the session and shows its progress. The records are passed on as they are answered
+func (screen *Screen) Start(ctx context.Context, session *quiz.Session) <-chan *quiz.Record {
+	screen.mu.Lock()
+	screen.total = len(session.Records)
+	screen.records = nil
+	for idx := range session.Records {
+		if session.Records[idx].Asked {
+			screen.records = append(screen.records, session.Records[idx])
+		}
+	}
+	screen.report = quiz.NewReport(screen.records, screen.opt.Matcher, screen.opt.Penalty)
+	if session.TimeLimit > 0 {
+		screen.deadline = time.Now().Add(session.TimeLimit)
+	}
+	screen.mu.Unlock()
+
+	//a record which is answered again after going back replaces its earlier response
+	positions := make(map[*quiz.Record]int)
+	respCh := make(chan *quiz.Record)
+	go func() {
+		defer close(respCh)
+		for record := range session.Start(ctx) {
+			screen.mu.Lock()
+			if idx, found := positions[record]; found {
+				screen.records[idx] = *record
+			} else {
+				positions[record] = len(screen.records)
+				screen.records = append(screen.records, *record)
+			}
+			screen.report = quiz.NewReport(screen.records, screen.opt.Matcher, screen.opt.Penalty)
+			screen.scroll = 0
+			screen.mu.Unlock()
+			respCh <- record
+		}
+	}()
+	return respCh
+}
+
+// Review shows the final score and lets the history be scrolled until the user
+// leaves with enter, q or escape, or ctx is done
+func (screen *Screen) Review(ctx context.Context) {
+	screen.mu.Lock()
+	screen.over, screen.scroll = true, 0
+	screen.mu.Unlock()
+	select {
+	case <-screen.exit:
+	case <-ctx.Done():
+	}
+}
+
+// refresh redraws the screen until it is closed, which keeps the countdown running
+func (screen *Screen) refresh() {
+	defer close(screen.rendered)
+	ticker := time.NewTicker(refreshRate)
+	defer ticker.Stop()
+	for {
+		screen.render()
+		select {
+		case <-ticker.C:
+		case <-screen.done:
+			return
+		}
+	}
+}
+
+func (screen *Screen) render() {
+	width, height, err := term.GetSize(int(screen.out.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = defaultWidth, defaultHeight
+	}
+	screen.mu.Lock()
+	lines, cursorRow, cursorCol := screen.layout(width, height, time.Now())
+	screen.mu.Unlock()
+
+	var sb strings.Builder
+	sb.WriteString(home)
+	for idx, line := range lines {
+		sb.WriteString(line + reset + clearLine)
+		if idx < len(lines)-1 {
+			sb.WriteString("\r\n")
+		}
+	}
+	sb.WriteString(clearBelow)
+	fmt.Fprintf(&sb, "\x1b[%d;%dH", cursorRow+1, cursorCol+1)
+	//goland:noinspection GoUnhandledErrorResult
+	screen.out.WriteString(sb.String())
+}
+
+// layout lays out the lines of the screen, which are cut to its width, and
+// finds where the cursor goes. It is called with the lock held
+func (screen *Screen) layout(width, height int, now time.Time) ([]string, int, int) {
+	var lines []string
+	add := func(style, format string, args ...any) {
+		lines = append(lines, style+truncate(fmt.Sprintf(format, args...), width))
+	}
+
+	countdown, style := "No time limit", bold
+	if !screen.deadline.IsZero() {
+		left := screen.deadline.Sub(now)
+		if countdown = "Time left " + formatClock(left); left < warnTime {
+			style = bold + red
+		}
+	}
+	title := "Quizbot"
+	add(style, "%s%*s", title, width-len(title), countdown)
+
+	answered := len(screen.records)
+	filled := 0
+	if screen.total > 0 {
+		filled = answered * progressWidth / screen.total
+	}
+	progress := fmt.Sprintf("[%s%s] %d/%d", strings.Repeat("#", filled), strings.Repeat("-", progressWidth-filled), answered, screen.total)
+	if (screen.opt.ShowScore || screen.over) && screen.report != nil {
+		progress += fmt.Sprintf("   Score: %d correct, %s points", screen.report.Correct, screen.report.Score())
+	}
+	add("", "%s", progress)
+	add("", "")
+
+	cursorRow, cursorCol := len(lines), 0
+	switch {
+	case screen.over:
+		add(bold, "The quiz is over. Press enter to see the report")
+		cursorRow = len(lines) - 1
+	case screen.question != nil:
+		prompt := strings.Split(screen.question.Prompt(screen.number, true), "\n")
+		for _, line := range prompt[:len(prompt)-1] {
+			add("", "%s", line)
+		}
+		input := prompt[len(prompt)-1] + string(screen.buffer)
+		cursorRow, cursorCol = len(lines), visibleWidth(input)
+		add("", "%s", input)
+		if !screen.questionDeadline.IsZero() {
+			add("", "Time left for this question: %s", formatClock(screen.questionDeadline.Sub(now)))
+		}
+	default:
+		input := "> " + string(screen.buffer)
+		cursorCol = visibleWidth(input)
+		add("", "%s", input)
+	}
+	if cursorCol >= width {
+		cursorCol = width - 1
+	}
+
+	add("", "")
+	if screen.message != "" && (screen.messageExpiry.IsZero() || now.Before(screen.messageExpiry)) {
+		add(bold, "%s", screen.message)
+	} else {
+		add("", "")
+	}
+	add("", "")
+
+	if screen.report == nil || len(screen.report.Questions) == 0 {
+		return lines, cursorRow, cursorCol
+	}
+	add(bold, "History (up/down to scroll)")
+	entries := screen.report.Questions
+	rows := height - len(lines)
+	if maxScroll := len(entries) - rows; screen.scroll > maxScroll {
+		screen.scroll = maxScroll
+	}
+	if screen.scroll < 0 {
+		screen.scroll = 0
+	}
+	//the latest response comes first
+	for idx := len(entries) - 1 - screen.scroll; idx >= 0 && len(lines) < height; idx-- {
+		entry := &entries[idx]
+		text := fmt.Sprintf("%3d. %s  > %s", entry.Number, entry.Question, entry.ShortResponse())
+		//a question which was not answered always shows why
+		if result := entry.Result(); screen.opt.ShowScore || screen.over || result == entry.Status {
+			text += "  (" + result + ")"
+		}
+		add("", "%s", text)
+	}
+	return lines, cursorRow, cursorCol
+}
+
+func formatClock(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	seconds := int((d + time.Second - 1) / time.Second)
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// truncate cuts the text to the width of the screen. The escape sequences of
+// its colors take no room and are kept
+func truncate(text string, width int) string {
+	var (
+		sb      strings.Builder
+		visible int
+		cut     bool
+	)
+	for idx := 0; idx < len(text); {
+		if end := escapeEnd(text, idx); end > idx {
+			sb.WriteString(text[idx:end])
+			idx = end
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(text[idx:])
+		//a wide character which would not fit is left out along with everything after it
+		if cells := runeWidth(r); !cut && visible+cells <= width {
+			sb.WriteRune(r)
+			visible += cells
+		} else {
+			cut = true
+		}
+		idx += size
+	}
+	return sb.String()
+}
+
+// visibleWidth is the number of cells the text takes in the terminal, leaving out its escape sequences
+func visibleWidth(text string) int {
+	visible := 0
+	for idx := 0; idx < len(text); {
+		if end := escapeEnd(text, idx); end > idx {
+			idx = end
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(text[idx:])
+		visible += runeWidth(r)
+		idx += size
+	}
+	return visible
+}
+
+// runeWidth is the number of cells the character takes in the terminal. East
+// Asian wide and full width characters take two, and combining marks none
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// escapeEnd finds the end of the escape sequence at idx, if there is one
+func escapeEnd(text string, idx int) int {
+	if !strings.HasPrefix(text[idx:], "\x1b[") {
+		return idx
+	}
+	for end := idx + 2; end < len(text); end++ {
+		if text[end] >= 0x40 && text[end] <= 0x7e {
+			return end + 1
+		}
+	}
+	return len(text)
+}
@@ -0,0 +1,163 @@
+package tui
+
+import (
+	"gophercises.com/quiz"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"hello world", 5, "hello"},
+		{"short", 10, "short"},
+		{red + "hello" + reset, 3, red + "hel" + reset},
+		{"\u65e5\u672c\u8a9e\u306e\u30af\u30a4\u30ba", 5, "\u65e5\u672c"},
+		{"\u65e5\u672c\u8a9e", 6, "\u65e5\u672c\u8a9e"},
+		{"\uff41\uff42\uff43", 4, "\uff41\uff42"},
+		{"cafe\u0301 au lait", 4, "cafe\u0301"},
+		{"ab\u65e5c", 3, "ab"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.text, tt.width); got != tt.want {
+			t.Errorf("truncate(%q, %d): want %q, got %q", tt.text, tt.width, tt.want, got)
+		}
+		if got := visibleWidth(truncate(tt.text, tt.width)); got > tt.width {
+			t.Errorf("truncate(%q, %d): want at most %d cells, got %d", tt.text, tt.width, tt.width, got)
+		}
+	}
+}
+
+func TestVisibleWidth(t *testing.T) {
+	tests := map[string]int{
+		"":                         0,
+		"> 42":                     4,
+		bold + red + "abc" + reset: 3,
+		"\u6771\u4eac":             4,
+		"\uff31\uff35\uff29\uff3a": 8,
+		"\uff76\uff80\uff76\uff85": 4,
+		"cafe\u0301":               4,
+		"> \u65e5\u672c ok":        9,
+	}
+	for text, want := range tests {
+		if got := visibleWidth(text); got != want {
+			t.Errorf("visibleWidth(%q): want %d, got %d", text, want, got)
+		}
+	}
+}
+
+func TestEscapeEnd(t *testing.T) {
+	tests := []struct {
+		text string
+		idx  int
+		want int
+	}{
+		{"abc", 0, 0},
+		{red + "abc", 0, len(red)},
+		{"a" + bold + "b", 1, 1 + len(bold)},
+		{"\x1b[12;3H", 0, 7},
+		{"\x1b[31", 0, 4},
+		{"\x1bx", 0, 0},
+	}
+	for _, tt := range tests {
+		if got := escapeEnd(tt.text, tt.idx); got != tt.want {
+			t.Errorf("escapeEnd(%q, %d): want %d, got %d", tt.text, tt.idx, tt.want, got)
+		}
+	}
+}
+
+func TestFormatClock(t *testing.T) {
+	tests := map[time.Duration]string{
+		-time.Second:                          "0:00",
+		0:                                     "0:00",
+		100 * time.Millisecond:                "0:01",
+		59 * time.Second:                      "0:59",
+		90 * time.Second:                      "1:30",
+		10*time.Minute + 500*time.Millisecond: "10:01",
+	}
+	for d, want := range tests {
+		if got := formatClock(d); got != want {
+			t.Errorf("formatClock(%v): want %s, got %s", d, want, got)
+		}
+	}
+}
+
+// newTestScreen is a screen which is not attached to a terminal, so that its
+// layout and keys can be tested
+func newTestScreen() *Screen {
+	matcher, _ := quiz.ParseMatcher(quiz.DefaultMatch)
+	return &Screen{
+		opt:       Option{Matcher: matcher},
+		submitted: make(chan string, 16),
+		pause:     make(chan struct{}, 1),
+		exit:      make(chan struct{}),
+	}
+}
+
+func TestScreen_layout(t *testing.T) {
+	now := time.Now()
+	screen := newTestScreen()
+	screen.total, screen.deadline = 4, now.Add(5*time.Second)
+	screen.Prompt(1, quiz.Question{Text: "\u65e5\u672c\u306e\u9996\u90fd\u306f\u3069\u3053\u3067\u3059\u304b\uff1f"}, time.Time{})
+	screen.buffer = []rune("\u6771\u4eac")
+
+	lines, row, col := screen.layout(30, 10, now)
+	if !strings.HasPrefix(lines[0], bold+red) || !strings.HasSuffix(lines[0], "Time left 0:05") {
+		t.Errorf("layout(): want a red countdown, got %q", lines[0])
+	}
+	if lines[1] != "[--------------------] 0/4" {
+		t.Errorf("layout(): want the progress, got %q", lines[1])
+	}
+	if lines[row] != "> \u6771\u4eac" || col != 6 {
+		t.Errorf("layout(): want the cursor after the wide response, got %q at column %d", lines[row], col)
+	}
+	for _, line := range lines {
+		if cells := visibleWidth(line); cells > 30 {
+			t.Errorf("layout(): want %q to fit in 30 cells, got %d", line, cells)
+		}
+	}
+
+	//the cursor stays on the screen when the response is wider
+	screen.buffer = []rune("a" + strings.Repeat("\u6771", 20))
+	if lines, _, col = screen.layout(30, 10, now); col != 29 || visibleWidth(lines[row]) != 29 {
+		t.Errorf("layout(): want the cursor in the last column and the response cut before a wide character, got %q at column %d", lines[row], col)
+	}
+}
+
+func TestScreen_layout_history(t *testing.T) {
+	now := time.Now()
+	screen := newTestScreen()
+	screen.total = 5
+	for idx, response := range []string{"1", "2", "3", "4", "5"} {
+		screen.records = append(screen.records, quiz.Record{Question: quiz.Question{Text: "Q" + response, Answer: "3"}, Asked: true, Answered: true, Response: response})
+		if idx == 4 {
+			screen.records[idx].Answered = false
+		}
+	}
+	screen.report = quiz.NewReport(screen.records, screen.opt.Matcher, 0)
+	screen.over = true
+
+	lines, row, _ := screen.layout(60, 12, now)
+	if lines[row] != bold+"The quiz is over. Press enter to see the report" {
+		t.Errorf("layout(): want the end of the quiz, got %q", lines[row])
+	}
+	if !strings.Contains(lines[1], "Score: 1 correct") {
+		t.Errorf("layout(): want the score once the quiz is over, got %q", lines[1])
+	}
+	if len(lines) != 12 {
+		t.Fatalf("layout(): want the history to fill the screen, got %d lines", len(lines))
+	}
+	if !strings.HasPrefix(lines[8], "  5. Q5") || !strings.HasSuffix(lines[8], "(timed out)") {
+		t.Errorf("layout(): want the latest response first, got %q", lines[8])
+	}
+
+	screen.scroll = 100
+	lines, _, _ = screen.layout(60, 12, now)
+	if screen.scroll != 1 || !strings.HasPrefix(lines[8], "  4. Q4") || !strings.HasPrefix(lines[11], "  1. Q1") {
+		t.Errorf("layout(): want the scroll to stop at the first response, got %d and %q", screen.scroll, lines[8:])
+	}
+}